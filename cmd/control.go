@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/daemon"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Exit codes of the control commands, besides 0 on success and 1 for any
+// other error.
+const (
+	exitNotRunning   = 3
+	exitInvalidState = 4
+	exitNoInterval   = 5
+)
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, pomodoro.ErrIntervalNotRunning):
+		return exitNotRunning
+	case errors.Is(err, pomodoro.ErrIntervalCompleted),
+		errors.Is(err, pomodoro.ErrInvalidState):
+		return exitInvalidState
+	case errors.Is(err, pomodoro.ErrNoInterval):
+		return exitNoInterval
+	default:
+		return 1
+	}
+}
+
+const controlLong = `
+
+Control commands go through the daemon when one is running, and otherwise
+work on the same database as the TUI, so they can be bound to editor or
+window-manager shortcuts. They print the resulting interval, as JSON with
+--json, and exit with:
+
+  0  success
+  1  any other error
+  3  no interval is running
+  4  the interval is in the wrong state for the action
+  5  there are no intervals yet`
+
+var startCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start or resume the current interval",
+	Long: `Start the current interval, or the next one if it finished.
+
+The interval only advances while a process is ticking it. With a daemon
+running, start returns right away. Otherwise it stays in the foreground until
+the interval ends, and Ctrl-C pauses it; --detach starts a daemon instead.` + controlLong,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runControl(cmd, pomodoro.Controller.Start)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the paused interval",
+	Long:  `Resume the paused interval. It takes the same flags as start.` + controlLong,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runControl(cmd, pomodoro.Controller.Resume)
+	},
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the running interval",
+	Long:  `Pause the running interval.` + controlLong,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return controlAction(cmd, pomodoro.Controller.Pause)
+	},
+}
+
+var endCmd = &cobra.Command{
+	Use:   "end",
+	Short: "End the running interval early",
+	Long:  `End the running interval early, counting it as done.` + controlLong,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return controlAction(cmd, pomodoro.Controller.End)
+	},
+}
+
+var skipCmd = &cobra.Command{
+	Use:   "skip",
+	Short: "Cancel the current interval and move on to the next",
+	Long: `Cancel the current interval, whether running, paused or not started, and
+print the interval that comes next. When the current interval already
+finished, the one that would come next is skipped instead.` + controlLong,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return controlAction(cmd, pomodoro.Controller.Skip)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{startCmd, resumeCmd, pauseCmd, endCmd, skipCmd, statusCmd} {
+		rootCmd.AddCommand(c)
+		c.SilenceUsage = true
+		c.Flags().BoolP("json", "j", false, "Print the interval as JSON")
+	}
+
+	for _, c := range []*cobra.Command{startCmd, resumeCmd} {
+		c.Flags().Bool("detach", false, "Start a daemon to tick the interval and return")
+	}
+}
+
+type controlFunc func(pomodoro.Controller) (pomodoro.Interval, error)
+
+// controlAction runs action and prints the interval it returns.
+func controlAction(cmd *cobra.Command, action controlFunc) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	ctl, closeCtl, err := getController()
+	if err != nil {
+		return err
+	}
+	defer closeCtl()
+
+	i, err := action(ctl)
+	if err != nil {
+		return err
+	}
+
+	return printInterval(cmd.OutOrStdout(), i, asJSON)
+}
+
+// runControl starts an interval with action. Without a daemon it ticks the
+// interval until it stops, or starts a daemon with --detach.
+func runControl(cmd *cobra.Command, action controlFunc) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	detach, _ := cmd.Flags().GetBool("detach")
+	out := cmd.OutOrStdout()
+
+	if _, err := daemon.Dial(socketPath()); err == nil {
+		return controlAction(cmd, action)
+	}
+
+	config, err := getConfig()
+	if err != nil {
+		return err
+	}
+
+	// Someone else is already ticking it.
+	if i, err := pomodoro.Current(config); err == nil && i.State == pomodoro.StateRunning {
+		return printInterval(out, i, asJSON)
+	}
+
+	if detach {
+		if err := spawnDaemon(cmd); err != nil {
+			return err
+		}
+
+		return controlAction(cmd, action)
+	}
+
+	n, snd, err := openNotifier()
+	if err != nil {
+		return err
+	}
+	defer snd.Close()
+
+	ctl, closeCtl, err := newRunner(config, n, snd)
+	if err != nil {
+		return err
+	}
+	defer closeCtl()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	events, cancel, err := ctl.Subscribe()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	i, err := action(ctl)
+	if err != nil {
+		return err
+	}
+
+	if err := printInterval(out, i, asJSON); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			// Only a pause or the end of the interval stops the ticking.
+			stopped := e.Type == pomodoro.EventPaused || e.Type == pomodoro.EventDone ||
+				e.Type == pomodoro.EventCancelled
+			if e.Interval.ID != i.ID || !stopped {
+				continue
+			}
+
+			return printInterval(out, e.Interval, asJSON)
+
+		case <-ctx.Done():
+			if err := closeCtl(); err != nil {
+				return err
+			}
+
+			i, err := ctl.Status()
+			if err != nil {
+				return err
+			}
+
+			return printInterval(out, i, asJSON)
+		}
+	}
+}
+
+// spawnDaemon starts "pomo daemon" with the global flags of cmd in a new
+// session, and waits until it answers on its socket.
+func spawnDaemon(cmd *cobra.Command) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"daemon"}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if cmd.InheritedFlags().Lookup(f.Name) == nil {
+			return
+		}
+
+		value := f.Value.String()
+		// Slices print as "[a,b]", which they do not parse back from.
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			value = strings.Join(s.GetSlice(), ",")
+		}
+
+		args = append(args, "--"+f.Name+"="+value)
+	})
+
+	child := exec.Command(exe, args...)
+	detachProcess(child)
+
+	if err := child.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("Daemon exited before listening")
+			}
+			return fmt.Errorf("detach: %w", err)
+
+		case <-timeout:
+			return errors.New("detach: timed out waiting for the daemon")
+
+		case <-ticker.C:
+			if _, err := daemon.Dial(socketPath()); err == nil {
+				return nil
+			}
+		}
+	}
+}
+
+func printInterval(out io.Writer, i pomodoro.Interval, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(out).Encode(pomodoro.NewIntervalJSON(i))
+	}
+
+	_, err := fmt.Fprintln(out, describeInterval(i))
+
+	return err
+}
+
+// describeInterval summarizes i in one line, such as
+// "Pomodoro running, 12m30s left".
+func describeInterval(i pomodoro.Interval) string {
+	switch i.State {
+	case pomodoro.StateRunning:
+		return fmt.Sprintf("%s running, %s left", i.Category, i.Remaining().Truncate(time.Second))
+	case pomodoro.StatePaused:
+		return fmt.Sprintf("%s paused, %s left", i.Category, i.Remaining().Truncate(time.Second))
+	case pomodoro.StateNotStarted:
+		return fmt.Sprintf("%s not started, %s planned", i.Category, i.PlannedDuration)
+	default:
+		return fmt.Sprintf("%s %s", i.Category, pomodoro.StateName(i.State))
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/daemon"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the timer in the background, controlled over a Unix socket",
+	Long: `Run the timer in the background, controlled over a Unix socket.
+
+While the daemon runs, the TUI and the control commands hand every action to
+it, so an interval keeps running after the terminal that started it closes.
+Stopping the daemon pauses the running interval, and an interval left
+running by a daemon that crashed is picked up again on the next start.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := getConfig()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return daemonAction(ctx, cmd.ErrOrStderr(), config, socketPath())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func daemonAction(
+	ctx context.Context,
+	log io.Writer,
+	config *pomodoro.IntervalConfig,
+	socket string,
+) error {
+	ln, err := daemon.Listen(socket)
+	if err != nil {
+		return err
+	}
+
+	n, snd, err := openNotifier()
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	defer snd.Close()
+
+	runner, closeRunner, err := newRunner(config, n, snd)
+	if err != nil {
+		ln.Close()
+		return err
+	}
+
+	go func() {
+		if err := runner.Recover(); err != nil {
+			fmt.Fprintln(log, "recover:", err)
+		}
+	}()
+
+	go func() {
+		for {
+			select {
+			case err := <-runner.Errors():
+				fmt.Fprintln(log, "timer:", err)
+			case <-ctx.Done():
+				ln.Close()
+				return
+			}
+		}
+	}()
+
+	fmt.Fprintln(log, "Listening on", socket)
+
+	if err := daemon.NewServer(runner).Serve(ln); err != nil {
+		closeRunner()
+		return err
+	}
+
+	return closeRunner()
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+	"github.com/spf13/cobra"
+)
+
+// dbMaintainer is implemented by storage backends that support the db
+// maintenance commands.
+type dbMaintainer interface {
+	Backup(dest string) error
+	Restore(src string) error
+	Merge(src string) (repository.MergeResult, error)
+}
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Back up, restore and merge the SQLite database",
+}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup FILE",
+	Short: "Write a consistent snapshot of the database to FILE",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := getMaintainer()
+		if err != nil {
+			return err
+		}
+
+		if err := db.Backup(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Backup written to %s\n", args[0])
+
+		return nil
+	},
+}
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore FILE",
+	Short: "Replace all intervals with the ones in the backup FILE",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := getMaintainer()
+		if err != nil {
+			return err
+		}
+
+		if err := db.Restore(args[0]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Restored from %s\n", args[0])
+
+		return nil
+	},
+}
+
+var dbMergeCmd = &cobra.Command{
+	Use:   "merge FILE",
+	Short: "Import the intervals of another database, skipping duplicates",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := getMaintainer()
+		if err != nil {
+			return err
+		}
+
+		res, err := db.Merge(args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(),
+			"Merged %s: %d imported, %d skipped\n",
+			args[0], res.Imported, res.Skipped)
+
+		if res.Cancelled > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(),
+				"%d intervals unfinished in %s were imported as cancelled\n",
+				res.Cancelled, args[0])
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+
+	dbCmd.AddCommand(dbBackupCmd)
+	dbCmd.AddCommand(dbRestoreCmd)
+	dbCmd.AddCommand(dbMergeCmd)
+}
+
+func getMaintainer() (dbMaintainer, error) {
+	repo, err := getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	db, ok := repo.(dbMaintainer)
+	if !ok {
+		return nil, fmt.Errorf("storage %q does not support db commands",
+			storageDSN())
+	}
+
+	return db, nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/spf13/cobra"
+)
+
+// dayLayout is the date format accepted by --from and --to.
+const dayLayout = "2006-01-02"
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export interval history as CSV, JSON or iCalendar",
+	Long: `Export interval history as CSV, JSON or iCalendar.
+
+CSV columns:
+  id,category,state,start_time,end_time,planned_seconds,actual_seconds
+
+JSON is an array of objects with the same fields. Times are RFC 3339 and
+start_time/end_time are null for intervals that never started.
+
+iCalendar output holds one VEVENT per completed pomodoro.`,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		format, _ := cmd.Flags().GetString("format")
+		fromFlag, _ := cmd.Flags().GetString("from")
+		toFlag, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+
+		exporter, err := pomodoro.NewExporter(format)
+		if err != nil {
+			return err
+		}
+
+		from, to, err := parseDayRange(fromFlag, toFlag)
+		if err != nil {
+			return err
+		}
+
+		repo, err := getRepo()
+		if err != nil {
+			return err
+		}
+
+		config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+		out := cmd.OutOrStdout()
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if cerr := f.Close(); err == nil {
+					err = cerr
+				}
+			}()
+
+			out = f
+		}
+
+		return exportAction(out, exporter, config, from, to)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringP("format", "f", "csv",
+		"Output format ("+strings.Join(pomodoro.ExportFormats(), ", ")+")")
+	exportCmd.Flags().String("from", "", "First day to export, as YYYY-MM-DD (default: all history)")
+	exportCmd.Flags().String("to", "", "Last day to export, as YYYY-MM-DD (default: today)")
+	exportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
+}
+
+func exportAction(
+	out io.Writer,
+	exporter pomodoro.Exporter,
+	config *pomodoro.IntervalConfig,
+	from, to time.Time,
+) error {
+	intervals, err := pomodoro.History(config, from, to)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	if err := exporter.Export(w, intervals); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// parseDayRange turns inclusive local days into the [from, to) range
+// covering them. Empty values mean the beginning of time and today.
+func parseDayRange(fromDay, toDay string) (time.Time, time.Time, error) {
+	var from, to time.Time
+
+	if fromDay != "" {
+		var err error
+		if from, err = time.ParseInLocation(dayLayout, fromDay, time.Local); err != nil {
+			return from, to, fmt.Errorf("invalid --from: %w", err)
+		}
+	}
+
+	if toDay == "" {
+		toDay = time.Now().Format(dayLayout)
+	}
+
+	to, err := time.ParseInLocation(dayLayout, toDay, time.Local)
+	if err != nil {
+		return from, to, fmt.Errorf("invalid --to: %w", err)
+	}
+	to = to.AddDate(0, 0, 1)
+
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("--from %s is after --to %s", fromDay, toDay)
+	}
+
+	return from, to, nil
+}
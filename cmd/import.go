@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import history from other pomodoro and time-tracking tools",
+	Long: `Import history from CSV exports of other tools.
+
+Formats:
+  generic  header with "start" and "end" or "duration", plus an optional
+           "category" column
+  toggl    Toggl Track detailed time entry export
+
+Rows overlapping an interval already recorded are reported as duplicates
+and skipped. Use --dry-run to preview the result without writing anything.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		parser, err := pomodoro.NewParser(format)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		rows, err := parser.Parse(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+
+		repo, err := getRepo()
+		if err != nil {
+			return err
+		}
+
+		config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+		return importAction(cmd.OutOrStdout(), config, rows, dryRun)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringP("format", "f", "generic",
+		"Input format ("+strings.Join(pomodoro.ImportFormats(), ", ")+")")
+	importCmd.Flags().BoolP("dry-run", "n", false, "Show what would be imported without writing")
+}
+
+func importAction(
+	out io.Writer,
+	config *pomodoro.IntervalConfig,
+	rows []pomodoro.ImportRow,
+	dryRun bool,
+) error {
+	sum, err := pomodoro.Import(config, rows, dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "imported"
+	if dryRun {
+		verb = "to import"
+	}
+
+	for _, r := range rows {
+		switch {
+		case r.Err != nil:
+			fmt.Fprintf(out, "line %d: error: %v\n", r.Line, r.Err)
+		case r.Duplicate:
+			fmt.Fprintf(out, "line %d: duplicate %s\n", r.Line, describeImport(r.Interval))
+		default:
+			fmt.Fprintf(out, "line %d: %s %s\n", r.Line, verb, describeImport(r.Interval))
+		}
+	}
+
+	fmt.Fprintf(out, "%d %s, %d duplicates, %d errors\n",
+		sum.Imported, verb, sum.Duplicates, sum.Errors)
+
+	return nil
+}
+
+func describeImport(i pomodoro.Interval) string {
+	return fmt.Sprintf("%s at %s for %s",
+		i.Category, i.StartTime.Local().Format("2006-01-02 15:04"), i.ActualDuration)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/daemon"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/sound"
+	"github.com/ZeroBl21/z-timer/notify"
+	"github.com/spf13/viper"
+)
+
+func getRepo() (pomodoro.Repository, error) {
+	return repository.Open(storageDSN())
+}
+
+// getConfig opens the repository with the interval durations set by flags
+// or the config file.
+func getConfig() (*pomodoro.IntervalConfig, error) {
+	repo, err := getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	return pomodoro.NewConfig(
+		repo,
+		viper.GetDuration("pomo"),
+		viper.GetDuration("short"),
+		viper.GetDuration("long"),
+	), nil
+}
+
+// storageDSN resolves the storage and db settings into a DSN.
+func storageDSN() string {
+	return resolveDSN(viper.GetString("storage"), viper.GetString("db"))
+}
+
+// resolveDSN turns storage into a DSN. A registered backend name such as
+// "sqlite" uses db as its location, and a value that starts
+// with a registered scheme, such as "sqlite:pomo.db", is already a DSN. Any
+// other value is a database file for the sqlite backend, such as
+// "/home/me/pomo.db", the Windows path "C:\Users\me\pomo.db" or the SQLite
+// URI "file:pomo.db?mode=ro".
+func resolveDSN(storage, db string) string {
+	backends := repository.Backends()
+
+	scheme, _, found := strings.Cut(storage, ":")
+	switch {
+	case storage == "":
+		return "sqlite:" + db
+	case !found && slices.Contains(backends, strings.ToLower(storage)):
+		return storage + ":" + db
+	case found && slices.Contains(backends, strings.ToLower(scheme)):
+		return storage
+	default:
+		return "sqlite:" + storage
+	}
+}
+
+// getController returns a client of the daemon when one is listening, and
+// otherwise a plain runner on the repository in this process. close releases
+// it; a local runner pauses the interval it is still ticking.
+//
+// Milestones, reminders, hooks and webhooks belong to the process ticking
+// the interval, so a short command acting on it does not fire them again.
+func getController() (ctl pomodoro.Controller, close func() error, err error) {
+	if c, err := daemon.Dial(socketPath()); err == nil {
+		return c, func() error { return nil }, nil
+	}
+
+	config, err := getConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := pomodoro.NewRunner(config)
+
+	return r, r.Close, nil
+}
+
+// newController returns a client of the daemon when one is listening, and
+// otherwise a runner from newRunner on config, for the long-lived commands
+// that tick the interval themselves.
+func newController(
+	config *pomodoro.IntervalConfig,
+	n notify.Notifier,
+	snd *sound.Player,
+) (ctl pomodoro.Controller, close func() error, err error) {
+	if c, err := daemon.Dial(socketPath()); err == nil {
+		return c, func() error { return nil }, nil
+	}
+
+	return newRunner(config, n, snd)
+}
+
+func socketPath() string {
+	if s := viper.GetString("socket"); s != "" {
+		return s
+	}
+
+	return daemon.DefaultSocket()
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import "testing"
+
+func TestResolveDSN(t *testing.T) {
+	testCases := []struct {
+		name    string
+		storage string
+		expDSN  string
+	}{
+		{name: "Empty", storage: "", expDSN: "sqlite:pomo.db"},
+		{name: "Backend", storage: "sqlite", expDSN: "sqlite:pomo.db"},
+		{name: "BackendUpper", storage: "JSON", expDSN: "JSON:pomo.db"},
+		{name: "Memory", storage: "memory", expDSN: "memory:pomo.db"},
+		{name: "DSN", storage: "json:/tmp/pomo.ndjson", expDSN: "json:/tmp/pomo.ndjson"},
+		{name: "AbsolutePath", storage: "/home/me/pomo.db", expDSN: "sqlite:/home/me/pomo.db"},
+		{name: "RelativePath", storage: "pomo-work.db", expDSN: "sqlite:pomo-work.db"},
+		{name: "WindowsPath", storage: `C:\Users\me\pomo.db`, expDSN: `sqlite:C:\Users\me\pomo.db`},
+		{name: "SQLiteURI", storage: "file:pomo.db?mode=ro", expDSN: "sqlite:file:pomo.db?mode=ro"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dsn := resolveDSN(tc.storage, "pomo.db")
+
+			if dsn != tc.expDSN {
+				t.Errorf("Expected %q, got %q instead.\n", tc.expDSN, dsn)
+			}
+		})
+	}
+}
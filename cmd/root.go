@@ -19,10 +19,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ZeroBl21/go-ztimer/pomodoro"
 	"github.com/ZeroBl21/go-ztimer/pomodoro/app"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/sound"
+	"github.com/ZeroBl21/z-timer/notify"
 	"github.com/spf13/cobra"
 
 	homedir "github.com/mitchellh/go-homedir"
@@ -36,26 +40,42 @@ var rootCmd = &cobra.Command{
 	Use:   "pomo",
 	Short: "Interactive Pomodoro Timer",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		repo, err := getRepo()
+		config, err := getConfig()
 		if err != nil {
 			return err
 		}
 
-		config := pomodoro.NewConfig(
-			repo,
-			viper.GetDuration("pomo"),
-			viper.GetDuration("short"),
-			viper.GetDuration("long"),
-		)
+		// Hook errors written to the terminal would garble the TUI.
+		if dir, err := os.UserCacheDir(); err == nil {
+			viper.SetDefault("hooks.log", filepath.Join(dir, "ztimer", "hooks.log"))
+			viper.SetDefault("webhooks.log", filepath.Join(dir, "ztimer", "webhooks.log"))
+			viper.SetDefault("notifications.log", filepath.Join(dir, "ztimer", "notifications.log"))
+		}
+
+		n, snd, err := openNotifier()
+		if err != nil {
+			return err
+		}
+		defer snd.Close()
 
-		return rootAction(os.Stdout, config)
+		ctl, closeCtl, err := newController(config, n, snd)
+		if err != nil {
+			return err
+		}
+		defer closeCtl()
+
+		return rootAction(os.Stdout, config, ctl)
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// Errors have already been printed by cobra, so only the exit code is left
+// to set.
 func Execute() {
-	cobra.CheckErr(rootCmd.Execute())
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(exitCode(err))
+	}
 }
 
 func init() {
@@ -63,15 +83,27 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ztimer.yaml)")
 
-	rootCmd.Flags().StringP("db", "d", "pomo.db", "Database file")
-	rootCmd.Flags().DurationP("pomo", "p", 25*time.Minute, "Pomodoro duration")
-	rootCmd.Flags().DurationP("short", "s", 5*time.Minute, "Short break duration")
-	rootCmd.Flags().DurationP("long", "l", 15*time.Minute, "Long break duration")
-
-	viper.BindPFlag("db", rootCmd.Flags().Lookup("db"))
-	viper.BindPFlag("pomo", rootCmd.Flags().Lookup("pomo"))
-	viper.BindPFlag("short", rootCmd.Flags().Lookup("short"))
-	viper.BindPFlag("long", rootCmd.Flags().Lookup("long"))
+	rootCmd.PersistentFlags().StringP("db", "d", "pomo.db", "Database file")
+	rootCmd.PersistentFlags().String("storage", "sqlite",
+		"Storage backend or DSN (sqlite, memory, json, sqlite:path/to/pomo.db)")
+	rootCmd.PersistentFlags().String("socket", "",
+		"Daemon socket (default $XDG_RUNTIME_DIR/ztimer.sock)")
+	rootCmd.PersistentFlags().StringSlice("notify", []string{"auto"},
+		"Notification backends: "+strings.Join(notify.Backends(), ", "))
+	rootCmd.Flags().String("layout", app.LayoutAuto,
+		"TUI layout: "+app.LayoutAuto+", "+strings.Join(app.Layouts, ", "))
+	rootCmd.PersistentFlags().DurationP("pomo", "p", 25*time.Minute, "Pomodoro duration")
+	rootCmd.PersistentFlags().DurationP("short", "s", 5*time.Minute, "Short break duration")
+	rootCmd.PersistentFlags().DurationP("long", "l", 15*time.Minute, "Long break duration")
+
+	viper.BindPFlag("db", rootCmd.PersistentFlags().Lookup("db"))
+	viper.BindPFlag("storage", rootCmd.PersistentFlags().Lookup("storage"))
+	viper.BindPFlag("socket", rootCmd.PersistentFlags().Lookup("socket"))
+	viper.BindPFlag("notify", rootCmd.PersistentFlags().Lookup("notify"))
+	viper.BindPFlag("layout", rootCmd.Flags().Lookup("layout"))
+	viper.BindPFlag("pomo", rootCmd.PersistentFlags().Lookup("pomo"))
+	viper.BindPFlag("short", rootCmd.PersistentFlags().Lookup("short"))
+	viper.BindPFlag("long", rootCmd.PersistentFlags().Lookup("long"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -97,11 +129,76 @@ func initConfig() {
 	}
 }
 
-func rootAction(out io.Writer, config *pomodoro.IntervalConfig) error {
-	a, err := app.New(config)
+func rootAction(
+	out io.Writer,
+	config *pomodoro.IntervalConfig,
+	ctl pomodoro.Controller,
+) error {
+	km := app.Keymap(viper.GetStringMapStringSlice("keymap")).Merge()
+	if err := km.Validate(); err != nil {
+		return fmt.Errorf("keymap: %w", err)
+	}
+
+	theme, err := loadTheme()
+	if err != nil {
+		return err
+	}
+
+	layout := viper.GetString("layout")
+	if err := app.ValidateLayout(layout); err != nil {
+		return err
+	}
+
+	a, err := app.New(config, ctl, app.Options{
+		Keymap: km,
+		Theme:  &theme,
+		Layout: layout,
+	})
 	if err != nil {
 		return err
 	}
 
 	return a.Run()
 }
+
+// loadTheme returns the theme named by the configuration, which may be one
+// of the custom themes it defines.
+func loadTheme() (app.Theme, error) {
+	custom := map[string]map[string]string{}
+	for name := range viper.GetStringMap("themes") {
+		custom[name] = viper.GetStringMapString("themes." + name)
+	}
+
+	t, err := app.LoadTheme(viper.GetString("theme"), custom)
+	if err != nil {
+		return t, fmt.Errorf("theme: %w", err)
+	}
+
+	return t, nil
+}
+
+// soundConfig reads the sound section of the configuration. Sounds are on
+// at full volume unless it says otherwise.
+func soundConfig() sound.Config {
+	c := sound.Config{
+		Volume:  100,
+		Players: viper.GetStringSlice("sound.players"),
+		Files:   map[string]string{},
+	}
+
+	if viper.IsSet("sound.volume") {
+		c.Volume = viper.GetInt("sound.volume")
+	}
+	if viper.IsSet("sound.enabled") && !viper.GetBool("sound.enabled") {
+		c.Volume = 0
+	}
+
+	for cue, file := range viper.GetStringMapString("sound.cues") {
+		if expanded, err := homedir.Expand(file); err == nil {
+			file = expanded
+		}
+		c.Files[cue] = file
+	}
+
+	return c
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/alert"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/hooks"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/sound"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/webhook"
+	"github.com/ZeroBl21/z-timer/notify"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+// newRunner returns a runner on config with the milestones, reminders,
+// hooks and webhooks of the config file attached, for the one process that
+// ticks the interval: the daemon, the TUI or a foreground start. The start
+// and end of intervals, milestones and idle reminders are announced through
+// n and snd. close stops the runner
+// and waits for the alerts, hooks and deliveries still running.
+func newRunner(
+	config *pomodoro.IntervalConfig,
+	n notify.Notifier,
+	snd *sound.Player,
+) (r *pomodoro.Runner, close func() error, err error) {
+	r = pomodoro.NewRunner(config)
+
+	// Run in reverse order by close.
+	var closers []func()
+	cleanup := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			r.Close()
+			cleanup()
+		}
+	}()
+
+	ms, err := milestones()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.SetMilestones(ms); err != nil {
+		return nil, nil, err
+	}
+
+	rp, err := reminderPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.SetReminders(rp); err != nil {
+		return nil, nil, err
+	}
+
+	logOut, closeLog, err := openLog("notifications.log")
+	if err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, closeLog)
+
+	a := alert.New(config, n, snd, logOut)
+	if err := a.Follow(r); err != nil {
+		return nil, nil, err
+	}
+	closers = append(closers, a.Wait)
+
+	if hc := hooksConfig(); len(hc.Commands) > 0 {
+		logOut, closeLog, err := openLog("hooks.log")
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, closeLog)
+
+		h, err := hooks.New(hc, logOut)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := h.Follow(r); err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, h.Wait)
+	}
+
+	wc, err := webhookConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(wc.Targets) > 0 {
+		logOut, closeLog, err := openLog("webhooks.log")
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, closeLog)
+
+		w, err := webhook.New(wc, logOut)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := w.Follow(r); err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, w.Close)
+	}
+
+	return r, func() error {
+		err := r.Close()
+		cleanup()
+
+		return err
+	}, nil
+}
+
+// openNotifier opens the notification backends and the sound player set by
+// the flags and the config file.
+func openNotifier() (notify.Notifier, *sound.Player, error) {
+	for kind, src := range viper.GetStringMapString("speech.templates") {
+		notify.SpeechTemplates[kind] = src
+	}
+
+	// The log backend without a file shares the log of the alerts, so that
+	// it stays off the terminal of the TUI.
+	var err error
+	if notify.LogFile, err = logPath("notifications.log"); err != nil {
+		return nil, nil, err
+	}
+
+	n, err := notify.OpenAll(viper.GetStringSlice("notify"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snd, err := sound.New(soundConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return n, snd, nil
+}
+
+// milestones returns the milestones of the config file, or
+// pomodoro.DefaultMilestones when it has none. An empty list disables them.
+func milestones() ([]pomodoro.Milestone, error) {
+	if !viper.IsSet("milestones") {
+		return pomodoro.DefaultMilestones, nil
+	}
+
+	var ms []pomodoro.Milestone
+	err := viper.UnmarshalKey("milestones", &ms)
+
+	return ms, err
+}
+
+func reminderPolicy() (pomodoro.ReminderPolicy, error) {
+	p := pomodoro.ReminderPolicy{Every: viper.GetDuration("reminders.every")}
+
+	var err error
+	if p.WorkHours, err = pomodoro.ParseWindow(viper.GetString("reminders.work_hours")); err != nil {
+		return p, err
+	}
+
+	p.QuietHours, err = pomodoro.ParseWindow(viper.GetString("reminders.quiet_hours"))
+
+	return p, err
+}
+
+func hooksConfig() hooks.Config {
+	return hooks.Config{
+		Commands:    viper.GetStringMapStringSlice("hooks.on"),
+		Timeout:     viper.GetDuration("hooks.timeout"),
+		Concurrency: viper.GetInt("hooks.concurrency"),
+	}
+}
+
+func webhookConfig() (webhook.Config, error) {
+	c := webhook.Config{
+		Timeout:     viper.GetDuration("webhooks.timeout"),
+		Backoff:     viper.GetDuration("webhooks.backoff"),
+		MaxBackoff:  viper.GetDuration("webhooks.max_backoff"),
+		MaxAttempts: viper.GetInt("webhooks.max_attempts"),
+	}
+
+	if err := viper.UnmarshalKey("webhooks.targets", &c.Targets); err != nil {
+		return c, err
+	}
+
+	queue := viper.GetString("webhooks.queue")
+	if queue == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return c, err
+		}
+		queue = filepath.Join(dir, "ztimer", "webhooks")
+	}
+
+	queue, err := homedir.Expand(queue)
+	c.QueueDir = queue
+
+	return c, err
+}
+
+// openLog opens the file set under key, or returns stderr.
+func openLog(key string) (io.Writer, func(), error) {
+	path, err := logPath(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path == "" {
+		return os.Stderr, func() {}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, func() { f.Close() }, nil
+}
+
+// logPath returns the file set under key, creating its directory, or an
+// empty path when none is set.
+func logPath(key string) (string, error) {
+	path := viper.GetString(key)
+	if path == "" {
+		return "", nil
+	}
+
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", err
+	}
+
+	return path, os.MkdirAll(filepath.Dir(path), 0o755)
+}
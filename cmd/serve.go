@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a local HTTP API and event stream",
+	Long: `Serve a local HTTP API and event stream for editor plugins and dashboards.
+
+  GET  /v1/interval             current interval
+  POST /v1/interval/{action}    start, resume, pause, end, skip, or extend
+                                with {"seconds": n}
+  GET  /v1/intervals            history, filtered by ?from= and ?to=
+  GET  /v1/events               Server-Sent Events: started, tick, paused,
+                                done, cancelled, milestone and idle
+  GET  /openapi.json            OpenAPI description
+
+Requests need the token as "Authorization: Bearer <token>" or ?token=. It is
+read from --token or the ZTIMER_TOKEN environment variable, and a random one
+is printed at startup otherwise. Actions go through the daemon when one is
+running.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		listen := viper.GetString("listen")
+		token := viper.GetString("token")
+
+		if token == "" {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			token = hex.EncodeToString(b)
+			fmt.Fprintln(cmd.ErrOrStderr(), "Token:", token)
+		}
+
+		config, err := getConfig()
+		if err != nil {
+			return err
+		}
+
+		n, snd, err := openNotifier()
+		if err != nil {
+			return err
+		}
+		defer snd.Close()
+
+		ctl, closeCtl, err := newController(config, n, snd)
+		if err != nil {
+			return err
+		}
+		defer closeCtl()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return serveAction(ctx, cmd.ErrOrStderr(), listen, api.NewHandler(ctl, config, token))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("listen", "127.0.0.1:7777", "Address to listen on")
+	serveCmd.Flags().String("token", "", "API token (default: $ZTIMER_TOKEN or a random one)")
+
+	viper.BindPFlag("listen", serveCmd.Flags().Lookup("listen"))
+	viper.BindPFlag("token", serveCmd.Flags().Lookup("token"))
+	viper.BindEnv("token", "ZTIMER_TOKEN")
+}
+
+func serveAction(ctx context.Context, log io.Writer, listen string, h http.Handler) error {
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Ends the event streams, which never finish on their own.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(log, "Listening on http://%s\n", ln.Addr())
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
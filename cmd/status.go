@@ -0,0 +1,394 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/daemon"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the current interval",
+	Long: `Print the current interval without changing it.
+
+--format takes a Go template or one of the presets tmux, waybar and i3bar.
+Templates see these fields:
+
+  .ID         interval id
+  .Category   Pomodoro, ShortBreak or LongBreak
+  .State      not_started, running, paused, done, cancelled, or none when
+              there are no intervals yet
+  .Active     true while running or paused
+  .Break      true for breaks
+  .Remaining  time left, as MM:SS or H:MM:SS
+  .Elapsed    time counted so far
+  .Planned    planned duration
+  .Percent    elapsed share of the planned duration, 0 to 100
+
+With --watch a line is printed on every change of the interval and every
+second, whatever its state, until interrupted.` + controlLong,
+	Example: `  pomo status --format '{{.Category}} {{.Remaining}}'
+  pomo status --format tmux
+  pomo status --format waybar --watch`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		format, _ := cmd.Flags().GetString("format")
+		watch, _ := cmd.Flags().GetBool("watch")
+
+		f, err := newStatusFormat(format, asJSON)
+		if err != nil {
+			return err
+		}
+
+		src, err := newStatusSource()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+
+		if !watch {
+			return statusAction(out, src, f)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return watchStatusAction(ctx, out, src, f)
+	},
+}
+
+// statusEvery is how often --watch prints a line without an event.
+const statusEvery = time.Second
+
+func init() {
+	statusCmd.Flags().StringP("format", "f", "",
+		"Go template or preset ("+strings.Join(statusPresetNames(), ", ")+")")
+	statusCmd.Flags().BoolP("watch", "w", false, "Print an updated line every second and on every change")
+	statusCmd.MarkFlagsMutuallyExclusive("format", "json")
+}
+
+// statusSource reads the current interval and follows its events. Unlike
+// getController it never runs a timer, so printing the status has no side
+// effects.
+type statusSource struct {
+	status func() (pomodoro.Interval, error)
+	// events follows the events of the interval until ctx is done.
+	events func(ctx context.Context) (<-chan pomodoro.Event, <-chan error, error)
+}
+
+// newStatusSource goes through the daemon when one is listening, and reads
+// the repository otherwise.
+func newStatusSource() (statusSource, error) {
+	if c, err := daemon.Dial(socketPath()); err == nil {
+		return statusSource{
+			status: c.Status,
+			events: func(ctx context.Context) (<-chan pomodoro.Event, <-chan error, error) {
+				events, cancel, err := c.Subscribe()
+				if err != nil {
+					return nil, nil, err
+				}
+
+				go func() {
+					<-ctx.Done()
+					cancel()
+				}()
+
+				return events, nil, nil
+			},
+		}, nil
+	}
+
+	config, err := getConfig()
+	if err != nil {
+		return statusSource{}, err
+	}
+
+	return statusSource{
+		status: func() (pomodoro.Interval, error) {
+			return pomodoro.Current(config)
+		},
+		events: func(ctx context.Context) (<-chan pomodoro.Event, <-chan error, error) {
+			events, errs := pomodoro.WatchEvents(ctx, config)
+			return events, errs, nil
+		},
+	}, nil
+}
+
+// statusData is what --format templates are executed with.
+type statusData struct {
+	ID        int64
+	Category  string
+	State     string
+	Active    bool
+	Break     bool
+	Remaining clock
+	Elapsed   clock
+	Planned   clock
+	Percent   int
+}
+
+func newStatusData(i pomodoro.Interval) statusData {
+	d := statusData{
+		ID:        i.ID,
+		Category:  i.Category,
+		State:     pomodoro.StateName(i.State),
+		Active:    i.State == pomodoro.StateRunning || i.State == pomodoro.StatePaused,
+		Break:     i.Category != pomodoro.CategoryPomodoro,
+		Remaining: clock(i.Remaining()),
+		Elapsed:   clock(i.ActualDuration),
+		Planned:   clock(i.PlannedDuration),
+	}
+
+	if i.PlannedDuration > 0 {
+		d.Percent = min(100, int(100*i.ActualDuration/i.PlannedDuration))
+	}
+
+	return d
+}
+
+// clock prints a duration the way timers show it, as MM:SS or H:MM:SS.
+type clock time.Duration
+
+func (c clock) String() string {
+	s := int(time.Duration(c).Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// statusFormat renders a status line with line, or describes the interval
+// in plain words or JSON when line is nil. In a --watch stream header is
+// written first and every line is followed by sep.
+type statusFormat struct {
+	header string
+	sep    string
+	line   func(statusData) (string, error)
+	json   bool
+}
+
+const tmuxTemplate = `{{if .Active}}#[fg={{if .Break}}green{{else}}red{{end}}]` +
+	`{{.Category}} {{.Remaining}}{{if eq .State "paused"}} (paused){{end}}#[default]{{end}}`
+
+var statusPresets = map[string]statusFormat{
+	"tmux":   {line: templateLine(template.Must(template.New("tmux").Parse(tmuxTemplate)))},
+	"waybar": {line: waybarLine},
+	"i3bar":  {header: `{"version":1}` + "\n[\n", sep: ",", line: i3barLine},
+}
+
+func statusPresetNames() []string {
+	names := make([]string, 0, len(statusPresets))
+	for n := range statusPresets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// newStatusFormat picks the preset named format, or parses it as a
+// template. Without a format the interval is described in plain words, or
+// as JSON.
+func newStatusFormat(format string, asJSON bool) (statusFormat, error) {
+	if f, ok := statusPresets[format]; ok {
+		return f, nil
+	}
+
+	if format == "" {
+		return statusFormat{json: asJSON}, nil
+	}
+
+	tmpl, err := template.New("status").Parse(format)
+	if err != nil {
+		return statusFormat{}, fmt.Errorf("invalid --format: %w", err)
+	}
+
+	return statusFormat{line: templateLine(tmpl)}, nil
+}
+
+func templateLine(tmpl *template.Template) func(statusData) (string, error) {
+	return func(d statusData) (string, error) {
+		var b strings.Builder
+		err := tmpl.Execute(&b, d)
+
+		return b.String(), err
+	}
+}
+
+// waybarLine renders the JSON expected by a waybar custom module with
+// "return-type": "json". The class is the interval state.
+func waybarLine(d statusData) (string, error) {
+	text := ""
+	if d.Active {
+		text = d.Category + " " + d.Remaining.String()
+	}
+
+	tooltip := "No intervals yet"
+	if d.State != "none" {
+		tooltip = fmt.Sprintf("%s %s, %s of %s", d.Category, d.State, d.Elapsed, d.Planned)
+	}
+
+	b, err := json.Marshal(struct {
+		Text       string `json:"text"`
+		Tooltip    string `json:"tooltip"`
+		Class      string `json:"class"`
+		Percentage int    `json:"percentage"`
+	}{
+		Text:       text,
+		Tooltip:    tooltip,
+		Class:      d.State,
+		Percentage: d.Percent,
+	})
+
+	return string(b), err
+}
+
+// i3barLine renders one status array of the i3bar protocol.
+func i3barLine(d statusData) (string, error) {
+	text, color := "", "#ffffff"
+	if d.Active {
+		text = d.Category + " " + d.Remaining.String()
+	}
+
+	switch {
+	case d.State == "paused":
+		color = "#ffff00"
+	case d.Active && d.Break:
+		color = "#00ff00"
+	case d.Active:
+		color = "#ff0000"
+	}
+
+	b, err := json.Marshal([]struct {
+		Name     string `json:"name"`
+		FullText string `json:"full_text"`
+		Color    string `json:"color"`
+	}{{Name: "pomo", FullText: text, Color: color}})
+
+	return string(b), err
+}
+
+// statusLine renders the interval i, read with err, with f. Templates and
+// presets render a missing interval as state "none" so that bars keep a
+// line.
+func statusLine(i pomodoro.Interval, err error, f statusFormat) (string, error) {
+	if errors.Is(err, pomodoro.ErrNoInterval) && f.line != nil {
+		d := newStatusData(i)
+		d.State = "none"
+		return f.line(d)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case f.line != nil:
+		return f.line(newStatusData(i))
+	case f.json:
+		b, err := json.Marshal(pomodoro.NewIntervalJSON(i))
+		return string(b), err
+	default:
+		return describeInterval(i), nil
+	}
+}
+
+func statusAction(out io.Writer, src statusSource, f statusFormat) error {
+	i, err := src.status()
+
+	line, err := statusLine(i, err, f)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(out, line)
+
+	return err
+}
+
+// watchStatusAction prints a status line for the current interval, another
+// for every event after it, and one every second in between, so that bars
+// never show a stale line, until ctx is done. The ticks of a running
+// interval are left to the second-by-second lines.
+func watchStatusAction(
+	ctx context.Context,
+	out io.Writer,
+	src statusSource,
+	f statusFormat,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, errs, err := src.events(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(out, f.header); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(statusEvery)
+	defer ticker.Stop()
+
+	i, statusErr := src.status()
+	show := true
+
+	for {
+		if show {
+			line, err := statusLine(i, statusErr, f)
+			if err != nil {
+				return err
+			}
+
+			if _, err := fmt.Fprintln(out, line+f.sep); err != nil {
+				return err
+			}
+		}
+		show = true
+
+		select {
+		case e, ok := <-events:
+			if !ok {
+				// The error that stopped the events is sent before they close.
+				select {
+				case err := <-errs:
+					return err
+				default:
+					return nil
+				}
+			}
+
+			if e.Type == pomodoro.EventTick {
+				show = false
+				continue
+			}
+
+			i, statusErr = e.Interval, nil
+
+		case <-ticker.C:
+			i, statusErr = src.status()
+
+		case err := <-errs:
+			return err
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
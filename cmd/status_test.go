@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func TestWatchStatusPaused(t *testing.T) {
+	paused := pomodoro.Interval{
+		ID:              1,
+		Category:        pomodoro.CategoryPomodoro,
+		PlannedDuration: 25 * time.Minute,
+		ActualDuration:  10 * time.Minute,
+		State:           pomodoro.StatePaused,
+	}
+
+	// No event arrives while the interval is paused.
+	src := statusSource{
+		status: func() (pomodoro.Interval, error) { return paused, nil },
+		events: func(ctx context.Context) (<-chan pomodoro.Event, <-chan error, error) {
+			return make(chan pomodoro.Event), nil, nil
+		},
+	}
+
+	f, err := newStatusFormat("{{.State}} {{.Remaining}}", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*statusEvery+statusEvery/2)
+	defer cancel()
+
+	var out strings.Builder
+	if err := watchStatusAction(ctx, &out, src, f); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %q instead.\n", lines)
+	}
+
+	for _, l := range lines {
+		if l != "paused 15:00" {
+			t.Errorf("Expected %q, got %q instead.\n", "paused 15:00", l)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/daemon"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print timer events as newline-delimited JSON",
+	Long: `Print one JSON object per timer event until interrupted:
+
+  {"seq":1,"type":"started","time":"...","interval":{...}}
+
+type is started, tick, paused, done, cancelled, milestone or idle, seq grows
+by one with every event, and interval holds all the fields of the interval as in pomo export
+--format json.
+
+With a daemon running the events come from it. Otherwise they are read from
+the database, so intervals ticked by the TUI or pomo start show up too. With
+--start the current interval is started first, in this process when there is
+no daemon.`,
+	Example:      `  pomo watch | jq -r 'select(.type == "done") | .interval.category'`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		start, _ := cmd.Flags().GetBool("start")
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		var (
+			ctl    pomodoro.Controller
+			events <-chan pomodoro.Event
+			errs   <-chan error
+		)
+
+		if c, err := daemon.Dial(socketPath()); err == nil {
+			ctl = c
+		} else {
+			config, err := getConfig()
+			if err != nil {
+				return err
+			}
+
+			if !start {
+				events, errs = pomodoro.WatchEvents(ctx, config)
+				return watchAction(ctx, cmd.OutOrStdout(), events, errs)
+			}
+
+			n, snd, err := openNotifier()
+			if err != nil {
+				return err
+			}
+			defer snd.Close()
+
+			r, closeRunner, err := newRunner(config, n, snd)
+			if err != nil {
+				return err
+			}
+			defer closeRunner()
+
+			ctl, errs = r, r.Errors()
+		}
+
+		events, cancel, err := ctl.Subscribe()
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
+		if start {
+			if _, err := ctl.Start(); err != nil {
+				return err
+			}
+		}
+
+		return watchAction(ctx, cmd.OutOrStdout(), events, errs)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().Bool("start", false, "Start the current interval before watching")
+}
+
+// watchAction writes every event as a JSON line until ctx is done or the
+// stream ends.
+func watchAction(
+	ctx context.Context,
+	out io.Writer,
+	events <-chan pomodoro.Event,
+	errs <-chan error,
+) error {
+	enc := json.NewEncoder(out)
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				// The error that stopped the events is sent before they close.
+				select {
+				case err := <-errs:
+					return err
+				default:
+					return nil
+				}
+			}
+
+			if err := enc.Encode(pomodoro.NewEventJSON(e)); err != nil {
+				return err
+			}
+
+		case err := <-errs:
+			return err
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
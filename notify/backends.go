@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("desktop", func(string) (Notifier, error) { return desktop{}, nil })
+	Register("dbus", openDBus)
+	Register("auto", openAuto)
+	Register("bell", func(string) (Notifier, error) { return NewTerminal(ModeBell) })
+	Register("osc", func(mode string) (Notifier, error) { return NewTerminal(mode) })
+	Register("log", openLog)
+	Register("command", openCommand)
+	Register("speech", openSpeech)
+	Register("none", func(string) (Notifier, error) { return none{}, nil })
+}
+
+// desktop shows the notification with the notifier of the OS: notify-send,
+// terminal-notifier or a PowerShell balloon.
+type desktop struct{}
+
+func (desktop) Send(n *Notify) error {
+	if err := n.Send(); err != nil {
+		return fmt.Errorf("desktop: %w", err)
+	}
+
+	return nil
+}
+
+// LogFile is the file of the log backend opened without a path, such as the
+// log of an application that draws on the terminal. Lines go to stderr when
+// it is empty.
+var LogFile string
+
+// logNotifier appends one line per notification to a file, or to stderr
+// without one.
+type logNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func openLog(path string) (Notifier, error) {
+	if path == "" {
+		path = LogFile
+	}
+
+	return &logNotifier{path: path}, nil
+}
+
+func (l *logNotifier) Send(n *Notify) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line := fmt.Sprintf("%s [%s] %s: %s\n",
+		time.Now().Format(time.RFC3339), n.severity, n.title, n.message)
+
+	if l.path == "" {
+		_, err := io.WriteString(os.Stderr, line)
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = io.WriteString(f, line)
+
+	return err
+}
+
+// commandNotifier runs a shell command with the notification in the
+// ZTIMER_TITLE, ZTIMER_MESSAGE and ZTIMER_SEVERITY environment variables.
+type commandNotifier struct {
+	cmdline string
+}
+
+func openCommand(cmdline string) (Notifier, error) {
+	if strings.TrimSpace(cmdline) == "" {
+		return nil, errors.New("Missing notification command, as in command:notify-me")
+	}
+
+	return commandNotifier{cmdline: cmdline}, nil
+}
+
+func (c commandNotifier) Send(n *Notify) error {
+	cmd := command("sh", "-c", c.cmdline)
+	cmd.Env = append(os.Environ(),
+		"ZTIMER_TITLE="+n.title,
+		"ZTIMER_MESSAGE="+n.message,
+		"ZTIMER_SEVERITY="+n.severity.String(),
+	)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("command: %w: %s", err, msg)
+		}
+		return fmt.Errorf("command: %w", err)
+	}
+
+	return nil
+}
+
+// none drops notifications.
+type none struct{}
+
+func (none) Send(*Notify) error { return nil }
@@ -0,0 +1,277 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusName      = "org.freedesktop.Notifications"
+	dbusPath      = "/org/freedesktop/Notifications"
+	dbusInterface = "org.freedesktop.Notifications"
+)
+
+var ErrNoNotification = errors.New("No notification to dismiss")
+
+// DBus sends notifications to the freedesktop notification server over the
+// D-Bus session bus, without needing notify-send. Clicked actions come back
+// as ActionInvoked signals and are passed to the handler of their
+// notification.
+type DBus struct {
+	// AppName and Icon are shown by the server; Icon is a freedesktop icon
+	// name or a file path.
+	AppName string
+	Icon    string
+	// Expire is how long notifications stay up. Zero leaves it to the
+	// server and a negative value keeps them until dismissed.
+	Expire time.Duration
+	// Stack shows every notification on its own instead of updating the
+	// previous one in place.
+	Stack bool
+
+	address string
+
+	mu   sync.Mutex
+	conn *dbus.Conn
+	// last is the ID of the latest notification, replaced by the next one.
+	last uint32
+
+	handlersMu sync.Mutex
+	handlers   map[uint32]func(key string)
+}
+
+// NewDBus returns a notifier for the bus at address, or for the session
+// bus when address is empty. The connection is made on the first Send.
+func NewDBus(address string) *DBus {
+	return &DBus{
+		AppName:  "ztimer",
+		address:  address,
+		handlers: map[uint32]func(string){},
+	}
+}
+
+func openDBus(arg string) (Notifier, error) {
+	d := NewDBus("")
+
+	for _, opt := range strings.Split(arg, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+
+		switch key {
+		case "":
+		case "expire":
+			if value == "never" {
+				d.Expire = -1
+				continue
+			}
+
+			exp, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("dbus: expire: %w", err)
+			}
+			d.Expire = exp
+		case "stack":
+			d.Stack = true
+		case "icon":
+			d.Icon = value
+		default:
+			return nil, fmt.Errorf("dbus: unknown option %q, expected expire, stack or icon", key)
+		}
+	}
+
+	return d, nil
+}
+
+// Urgency returns the freedesktop urgency level of s.
+func (s Severity) Urgency() byte {
+	switch s {
+	case SeverityLow:
+		return 0
+	case SeverityUrgent:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func (d *DBus) Send(n *Notify) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	replaces := d.last
+	if d.Stack {
+		replaces = 0
+	}
+
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(n.severity.Urgency()),
+	}
+
+	// Pairs of key and label.
+	actions := []string{}
+	for _, a := range n.actions {
+		actions = append(actions, a.Key, a.Label)
+	}
+
+	var id uint32
+	err := d.call("Notify", &id,
+		d.AppName, replaces, d.Icon, n.title, n.message,
+		actions, hints, d.expireTimeout(),
+	)
+	if err != nil {
+		return fmt.Errorf("dbus: %w", err)
+	}
+
+	d.last = id
+
+	// A replaced notification takes over the ID, so the new handler, or
+	// none, applies from now on.
+	d.handlersMu.Lock()
+	if len(n.actions) > 0 && n.handle != nil {
+		d.handlers[id] = n.handle
+	} else {
+		delete(d.handlers, id)
+	}
+	d.handlersMu.Unlock()
+
+	return nil
+}
+
+// Dismiss closes the latest notification.
+func (d *DBus) Dismiss() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.last == 0 {
+		return ErrNoNotification
+	}
+
+	if err := d.call("CloseNotification", nil, d.last); err != nil {
+		return fmt.Errorf("dbus: %w", err)
+	}
+
+	d.last = 0
+
+	return nil
+}
+
+// Close closes the connection to the bus.
+func (d *DBus) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.conn == nil {
+		return nil
+	}
+
+	err := d.conn.Close()
+	d.conn = nil
+
+	return err
+}
+
+// expireTimeout converts Expire to milliseconds, where -1 is the server
+// default and 0 never expires.
+func (d *DBus) expireTimeout() int32 {
+	switch {
+	case d.Expire == 0:
+		return -1
+	case d.Expire < 0:
+		return 0
+	default:
+		return int32(d.Expire.Milliseconds())
+	}
+}
+
+// call calls method on the notification server, connecting first if
+// needed. A broken connection is dropped so that the next call reconnects.
+// The caller holds d.mu.
+func (d *DBus) call(method string, ret any, args ...any) error {
+	if d.conn == nil {
+		conn, err := d.connect()
+		if err != nil {
+			return err
+		}
+
+		if err := d.listen(conn); err != nil {
+			conn.Close()
+			return err
+		}
+
+		d.conn = conn
+	}
+
+	call := d.conn.Object(dbusName, dbusPath).Call(dbusInterface+"."+method, 0, args...)
+	if call.Err != nil {
+		if !d.conn.Connected() {
+			d.conn.Close()
+			d.conn = nil
+		}
+		return call.Err
+	}
+
+	if ret == nil {
+		return nil
+	}
+
+	return call.Store(ret)
+}
+
+func (d *DBus) connect() (*dbus.Conn, error) {
+	if d.address == "" {
+		return dbus.ConnectSessionBus()
+	}
+
+	return dbus.Connect(d.address)
+}
+
+// listen dispatches the signals of the notification server on conn until
+// it is closed.
+func (d *DBus) listen(conn *dbus.Conn) error {
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(dbusPath),
+		dbus.WithMatchInterface(dbusInterface),
+	); err != nil {
+		return err
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	go func() {
+		for s := range signals {
+			d.signal(s)
+		}
+	}()
+
+	return nil
+}
+
+func (d *DBus) signal(s *dbus.Signal) {
+	if len(s.Body) < 2 {
+		return
+	}
+
+	id, ok := s.Body[0].(uint32)
+	if !ok {
+		return
+	}
+
+	d.handlersMu.Lock()
+	defer d.handlersMu.Unlock()
+
+	switch s.Name {
+	case dbusInterface + ".ActionInvoked":
+		key, _ := s.Body[1].(string)
+		if handle, ok := d.handlers[id]; ok {
+			go handle(key)
+		}
+
+	case dbusInterface + ".NotificationClosed":
+		delete(d.handlers, id)
+	}
+}
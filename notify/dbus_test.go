@@ -0,0 +1,279 @@
+//go:build !integration
+// +build !integration
+
+package notify
+
+import (
+	"bufio"
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+type notifyCall struct {
+	replaces uint32
+	summary  string
+	body     string
+	urgency  byte
+	expire   int32
+	actions  []string
+}
+
+// fakeServer implements the methods of org.freedesktop.Notifications that
+// the DBus notifier calls.
+type fakeServer struct {
+	conn *dbus.Conn
+
+	mu     sync.Mutex
+	next   uint32
+	calls  []notifyCall
+	closed []uint32
+}
+
+func (f *fakeServer) Notify(
+	appName string,
+	replaces uint32,
+	icon, summary, body string,
+	actions []string,
+	hints map[string]dbus.Variant,
+	expire int32,
+) (uint32, *dbus.Error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var urgency byte
+	if v, ok := hints["urgency"]; ok {
+		urgency, _ = v.Value().(byte)
+	}
+
+	f.calls = append(f.calls, notifyCall{replaces, summary, body, urgency, expire, actions})
+
+	id := replaces
+	if id == 0 {
+		f.next++
+		id = f.next
+	}
+
+	return id, nil
+}
+
+func (f *fakeServer) CloseNotification(id uint32) *dbus.Error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = append(f.closed, id)
+
+	return nil
+}
+
+// emit sends a signal of the notification server, as when the user clicks
+// an action.
+func (f *fakeServer) emit(t *testing.T, name string, args ...any) {
+	t.Helper()
+
+	if err := f.conn.Emit(dbusPath, dbusInterface+"."+name, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recorded returns copies of the calls received so far.
+func (f *fakeServer) recorded() ([]notifyCall, []uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]notifyCall(nil), f.calls...), append([]uint32(nil), f.closed...)
+}
+
+// startBus runs a private session bus with a fake notification server on
+// it and returns its address.
+func startBus(t *testing.T) (string, *fakeServer) {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("Skipped: dbus-daemon not found")
+	}
+
+	cmd := exec.Command(daemon, "--session", "--nofork", "--nopidfile", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	address = strings.TrimSpace(address)
+
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	srv := &fakeServer{conn: conn}
+	if err := conn.Export(srv, dbusPath, dbusInterface); err != nil {
+		t.Fatal(err)
+	}
+
+	reply, err := conn.RequestName(dbusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Expected to own %s, got %v, %v instead\n", dbusName, reply, err)
+	}
+
+	return address, srv
+}
+
+func TestDBus(t *testing.T) {
+	testCases := []struct {
+		name        string
+		stack       bool
+		expire      time.Duration
+		expReplaces []uint32
+		expExpire   int32
+	}{
+		{name: "Replace", expReplaces: []uint32{0, 1, 1}, expExpire: -1},
+		{name: "Stack", stack: true, expReplaces: []uint32{0, 0, 0}, expExpire: -1},
+		{name: "Expire", expire: 5 * time.Second, expReplaces: []uint32{0, 1, 1}, expExpire: 5000},
+		{name: "NeverExpire", expire: -1, expReplaces: []uint32{0, 1, 1}, expExpire: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, srv := startBus(t)
+
+			d := NewDBus(address)
+			d.Stack = tc.stack
+			d.Expire = tc.expire
+			defer d.Close()
+
+			severities := []Severity{SeverityLow, SeverityNormal, SeverityUrgent}
+			for _, s := range severities {
+				if err := d.Send(New("Pomodoro", "msg "+s.String(), s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			calls, _ := srv.recorded()
+			if len(calls) != len(severities) {
+				t.Fatalf("Expected %d calls, got %d instead\n", len(severities), len(calls))
+			}
+
+			for i, c := range calls {
+				if c.replaces != tc.expReplaces[i] {
+					t.Errorf("Expected call %d to replace %d, got %d instead\n", i, tc.expReplaces[i], c.replaces)
+				}
+				if c.urgency != severities[i].Urgency() {
+					t.Errorf("Expected urgency %d, got %d instead\n", severities[i].Urgency(), c.urgency)
+				}
+				if c.expire != tc.expExpire {
+					t.Errorf("Expected expire %d, got %d instead\n", tc.expExpire, c.expire)
+				}
+				if c.summary != "Pomodoro" {
+					t.Errorf("Expected %q, got %q instead\n", "Pomodoro", c.summary)
+				}
+			}
+		})
+	}
+}
+
+func TestDBusDismiss(t *testing.T) {
+	address, srv := startBus(t)
+
+	d := NewDBus(address)
+	defer d.Close()
+
+	if err := d.Dismiss(); !errors.Is(err, ErrNoNotification) {
+		t.Errorf("Expected error %q, got %v instead\n", ErrNoNotification, err)
+	}
+
+	if err := d.Send(New("Pomodoro", "msg", SeverityNormal)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Dismiss(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, closed := srv.recorded(); len(closed) != 1 || closed[0] != 1 {
+		t.Errorf("Expected notification 1 to be closed, got %v instead\n", closed)
+	}
+
+	// The next one is new again.
+	if err := d.Send(New("Pomodoro", "msg", SeverityNormal)); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls, _ := srv.recorded(); calls[1].replaces != 0 {
+		t.Errorf("Expected a new notification, got replaces %d instead\n", calls[1].replaces)
+	}
+}
+
+func TestDBusActions(t *testing.T) {
+	address, srv := startBus(t)
+
+	d := NewDBus(address)
+	defer d.Close()
+
+	keys := make(chan string, 1)
+	n := New("Pomodoro", "Pomodoro finished!", SeverityNormal).WithActions(
+		func(key string) { keys <- key },
+		Action{Key: "start", Label: "Start break"},
+		Action{Key: "skip", Label: "Skip"},
+	)
+
+	if err := d.Send(n); err != nil {
+		t.Fatal(err)
+	}
+
+	calls, _ := srv.recorded()
+	expActions := "start Start break skip Skip"
+	if got := strings.Join(calls[0].actions, " "); got != expActions {
+		t.Errorf("Expected actions %q, got %q instead\n", expActions, got)
+	}
+
+	// Signals of other notifications are ignored.
+	srv.emit(t, "ActionInvoked", uint32(42), "start")
+	srv.emit(t, "ActionInvoked", uint32(1), "skip")
+
+	select {
+	case key := <-keys:
+		if key != "skip" {
+			t.Errorf("Expected %q, got %q instead\n", "skip", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the action")
+	}
+
+	// Once closed, its actions no longer reach the handler.
+	srv.emit(t, "NotificationClosed", uint32(1), uint32(2))
+	srv.emit(t, "ActionInvoked", uint32(1), "start")
+
+	select {
+	case key := <-keys:
+		t.Errorf("Expected no action after close, got %q instead\n", key)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestDBusNoServer(t *testing.T) {
+	d := NewDBus("unix:path=/nonexistent/bus")
+
+	if err := d.Send(New("Pomodoro", "msg", SeverityNormal)); err == nil {
+		t.Error("Expected an error without a bus")
+	}
+}
@@ -2,4 +2,7 @@ module github.com/ZeroBl21/z-timer/notify
 
 go 1.24.0
 
-require golang.org/x/text v0.22.0
+require (
+	github.com/godbus/dbus/v5 v5.1.0
+	golang.org/x/text v0.22.0
+)
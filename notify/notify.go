@@ -1,12 +1,24 @@
 package notify
 
 import (
+	"errors"
+	"os/exec"
 	"runtime"
 
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
 
+// command runs external programs, lookPath finds them and session sends
+// over the D-Bus session bus on Linux; tests replace them. session is shared
+// by every Send, so that each notification replaces the previous one instead
+// of stacking.
+var (
+	command           = exec.Command
+	lookPath          = exec.LookPath
+	session  Notifier = NewDBus("")
+)
+
 type Severity int
 
 const (
@@ -19,6 +31,17 @@ type Notify struct {
 	title    string
 	message  string
 	severity Severity
+	actions  []Action
+	handle   func(key string)
+	kind     string
+	data     map[string]string
+}
+
+// Action is a button on a notification. Key is passed back to the handler
+// when it is clicked.
+type Action struct {
+	Key   string
+	Label string
 }
 
 func New(title, message string, severity Severity) *Notify {
@@ -29,6 +52,57 @@ func New(title, message string, severity Severity) *Notify {
 	}
 }
 
+// WithActions adds buttons to the notification and calls handle with the
+// key of the one clicked. Backends without actions show the notification
+// without them, so handle may never be called.
+func (n *Notify) WithActions(handle func(key string), actions ...Action) *Notify {
+	n.actions = append(n.actions, actions...)
+	n.handle = handle
+
+	return n
+}
+
+// WithKind says what the notification is about, such as "finished", and
+// gives the values a backend may fill a template for that kind with.
+func (n *Notify) WithKind(kind string, data map[string]string) *Notify {
+	n.kind = kind
+	n.data = data
+
+	return n
+}
+
+func (n *Notify) Title() string           { return n.title }
+func (n *Notify) Message() string         { return n.message }
+func (n *Notify) Severity() Severity      { return n.severity }
+func (n *Notify) Actions() []Action       { return n.actions }
+func (n *Notify) Kind() string            { return n.kind }
+func (n *Notify) Data() map[string]string { return n.data }
+
+// Notifier delivers a notification somewhere: the desktop, the terminal,
+// a file. Send on a *Notify itself uses the desktop notifier of the OS.
+type Notifier interface {
+	Send(n *Notify) error
+}
+
+// Multi sends every notification to all of ns and returns their errors
+// joined.
+func Multi(ns ...Notifier) Notifier {
+	return multi(ns)
+}
+
+type multi []Notifier
+
+func (m multi) Send(n *Notify) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Send(n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (s Severity) String() string {
 	sev := "low"
 
@@ -1,15 +1,20 @@
 package notify
 
-import "os/exec"
-
-var command = exec.Command
+import "errors"
 
+// Send shows the notification over D-Bus, falling back to notify-send when
+// no notification server answers on the session bus.
 func (n *Notify) Send() error {
+	dbusErr := session.Send(n)
+	if dbusErr == nil {
+		return nil
+	}
+
 	notifyCmdName := "notify-send"
 
-	notifyCmd, err := exec.LookPath(notifyCmdName)
+	notifyCmd, err := lookPath(notifyCmdName)
 	if err != nil {
-		return err
+		return errors.Join(dbusErr, err)
 	}
 
 	notifyCommand := command(
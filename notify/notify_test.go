@@ -4,6 +4,7 @@
 package notify
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -88,13 +89,26 @@ func TestSeverityString(t *testing.T) {
 func TestSend(t *testing.T) {
 	n := New("test title", "test msg", SeverityNormal)
 
+	origSession, origCommand, origLookPath := session, command, lookPath
+	t.Cleanup(func() { session, command, lookPath = origSession, origCommand, origLookPath })
+
+	session = noBus{}
 	command = mockCmd
+	lookPath = func(name string) (string, error) { return name, nil }
 
 	if err := n.Send(); err != nil {
 		t.Error(err)
 	}
 }
 
+// noBus fails like a session bus without a notification server, so that
+// Send falls back to the notifier command.
+type noBus struct{}
+
+func (noBus) Send(*Notify) error {
+	return errors.New("no session bus")
+}
+
 func TestHelperProcess(t *testing.T) {
 	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
 		return
@@ -1,16 +1,11 @@
 package notify
 
-import (
-	"fmt"
-	"os/exec"
-)
-
-var command = exec.Command
+import "fmt"
 
 func (n *Notify) Send() error {
 	notifyCmdName := "powershell.exe"
 
-	notifyCmd, err := exec.LookPath(notifyCmdName)
+	notifyCmd, err := lookPath(notifyCmdName)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownBackend = errors.New("Unknown notification backend")
+
+// Opener creates a notifier from the argument of its spec, the part after
+// the colon in "log:/tmp/pomo.log". It is empty for a bare name.
+type Opener func(arg string) (Notifier, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Opener{}
+)
+
+// Register makes a notifier available under the given name. It panics if
+// the name is registered twice.
+func Register(name string, open Opener) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if open == nil {
+		panic("notify: Register opener is nil")
+	}
+	if _, ok := backends[name]; ok {
+		panic("notify: Register called twice for backend " + name)
+	}
+
+	backends[name] = open
+}
+
+// Backends returns the sorted list of registered names.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Open creates the notifier described by spec, a backend name optionally
+// followed by a colon and its argument.
+func Open(spec string) (Notifier, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+
+	backendsMu.RLock()
+	open, ok := backends[strings.ToLower(strings.TrimSpace(name))]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)",
+			ErrUnknownBackend, name, strings.Join(Backends(), ", "))
+	}
+
+	return open(arg)
+}
+
+// OpenAll opens every spec and combines them with Multi.
+func OpenAll(specs []string) (Notifier, error) {
+	ns := make([]Notifier, 0, len(specs))
+
+	for _, spec := range specs {
+		n, err := Open(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		ns = append(ns, n)
+	}
+
+	if len(ns) == 1 {
+		return ns[0], nil
+	}
+
+	return Multi(ns...), nil
+}
@@ -0,0 +1,160 @@
+//go:build !integration
+// +build !integration
+
+package notify
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failing struct{ err error }
+
+func (f failing) Send(*Notify) error { return f.err }
+
+func TestOpen(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "notify.log")
+
+	testCases := []struct {
+		spec   string
+		expErr error
+	}{
+		{spec: "desktop"},
+		{spec: "bell"},
+		{spec: "osc"},
+		{spec: "osc:777"},
+		{spec: "auto"},
+		{spec: "none"},
+		{spec: "log"},
+		{spec: "log:" + logFile},
+		{spec: "command:echo hi"},
+		{spec: "dbus:expire=5s,stack"},
+		{spec: "speech:espeak-ng"},
+		{spec: "Desktop"},
+		{spec: "pager", expErr: ErrUnknownBackend},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			n, err := Open(tc.spec)
+			if tc.expErr != nil {
+				if !errors.Is(err, tc.expErr) {
+					t.Errorf("Expected error %q, got %v instead\n", tc.expErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n == nil {
+				t.Error("Expected a notifier, got nil instead")
+			}
+		})
+	}
+}
+
+func TestLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "notify.log")
+
+	n, err := Open("log:" + logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []string{"Focus on your task", "Pomodoro finished!"} {
+		if err := n.Send(New("Pomodoro", msg, SeverityNormal)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d instead\n", len(lines))
+	}
+
+	exp := "Pomodoro: Pomodoro finished!"
+	if !strings.HasSuffix(lines[1], exp) {
+		t.Errorf("Expected %q to end with %q\n", lines[1], exp)
+	}
+}
+
+func TestLogFile(t *testing.T) {
+	defer func(f string) { LogFile = f }(LogFile)
+	LogFile = filepath.Join(t.TempDir(), "notify.log")
+
+	n, err := Open("log")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := n.Send(New("Pomodoro", "Halfway", SeverityLow)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(LogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "Pomodoro: Halfway\n"
+	if !strings.HasSuffix(string(data), exp) {
+		t.Errorf("Expected %q to end with %q\n", data, exp)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	// TestSend leaves its mock in place.
+	defer func(c func(string, ...string) *exec.Cmd) { command = c }(command)
+	command = exec.Command
+
+	out := filepath.Join(t.TempDir(), "out")
+
+	n, err := Open(`command:echo "$ZTIMER_TITLE|$ZTIMER_MESSAGE" > ` + out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := n.Send(New("Pomodoro", "Take a break", SeverityLow)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "Pomodoro|Take a break\n"
+	if string(data) != exp {
+		t.Errorf("Expected %q, got %q instead\n", exp, string(data))
+	}
+
+	if _, err := Open("command:"); err == nil {
+		t.Error("Expected an error for a missing command")
+	}
+}
+
+func TestMulti(t *testing.T) {
+	errA := errors.New("A failed")
+	errB := errors.New("B failed")
+
+	n := Multi(failing{errA}, failing{nil}, failing{errB})
+
+	err := n.Send(New("Pomodoro", "msg", SeverityNormal))
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Expected both errors, got %v instead\n", err)
+	}
+
+	if err := Multi(failing{nil}).Send(New("Pomodoro", "msg", SeverityNormal)); err != nil {
+		t.Errorf("Expected no error, got %v instead\n", err)
+	}
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var ErrNoSpeaker = errors.New("No text-to-speech program found")
+
+// Speakers are the text-to-speech programs tried, in order, when none is
+// given. Each gets the text as its last argument.
+var Speakers = []string{"spd-say", "espeak-ng", "espeak", "say"}
+
+// speakerArgs are the arguments passed before the text.
+var speakerArgs = map[string][]string{
+	// Without --wait, spd-say returns before speaking and the next
+	// announcement cuts this one off.
+	"spd-say": {"--wait"},
+}
+
+// SpeechTemplates are the text/template sources of the spoken text by kind
+// of notification, as set with WithKind. They see the data of the
+// notification along with its Title, Message and Severity, and the kinds
+// without a template speak the message. Changes apply to the speech
+// notifiers opened afterwards.
+var SpeechTemplates = map[string]string{
+	"finished": `{{.Category}} done{{if eq .Next "pomodoro"}}, time to focus{{else if .Next}}, take a {{.NextMinutes}} minute {{.Next}}{{end}}`,
+}
+
+// Speech reads notifications aloud with a local text-to-speech program,
+// for when nobody watches the screen.
+type Speech struct {
+	// Program is the text-to-speech program, or the first installed of
+	// Speakers when empty.
+	Program string
+
+	templates map[string]*template.Template
+
+	// mu keeps announcements from talking over each other.
+	mu sync.Mutex
+}
+
+// NewSpeech returns a notifier speaking through program with the templates
+// of SpeechTemplates.
+func NewSpeech(program string) (*Speech, error) {
+	s := &Speech{
+		Program:   program,
+		templates: map[string]*template.Template{},
+	}
+
+	for kind, src := range SpeechTemplates {
+		tmpl, err := template.New(kind).Option("missingkey=zero").Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("speech: template %q: %w", kind, err)
+		}
+
+		s.templates[kind] = tmpl
+	}
+
+	return s, nil
+}
+
+func openSpeech(program string) (Notifier, error) {
+	return NewSpeech(strings.TrimSpace(program))
+}
+
+func (s *Speech) Send(n *Notify) error {
+	text, err := s.Text(n)
+	if err != nil {
+		return err
+	}
+
+	exe, name, err := s.speaker()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	args := append(slices.Clone(speakerArgs[filepath.Base(name)]), text)
+	if out, err := command(exe, args...).CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("speech: %s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("speech: %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Text returns what is said for n.
+func (s *Speech) Text(n *Notify) (string, error) {
+	tmpl, ok := s.templates[n.kind]
+	if !ok {
+		return n.message, nil
+	}
+
+	data := map[string]string{
+		"Title":    n.title,
+		"Message":  n.message,
+		"Severity": n.severity.String(),
+	}
+	for k, v := range n.data {
+		data[k] = v
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("speech: %w", err)
+	}
+
+	return b.String(), nil
+}
+
+// speaker returns the path and name of the program to run.
+func (s *Speech) speaker() (exe, name string, err error) {
+	names := Speakers
+	if s.Program != "" {
+		names = []string{s.Program}
+	}
+
+	for _, name := range names {
+		if exe, err := lookPath(name); err == nil {
+			return exe, name, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("speech: %w, tried %s", ErrNoSpeaker, strings.Join(names, ", "))
+}
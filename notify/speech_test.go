@@ -0,0 +1,139 @@
+//go:build !integration
+// +build !integration
+
+package notify
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// mockSpeakers makes only the installed programs available, failing when
+// fail is set, and records the command lines run.
+func mockSpeakers(t *testing.T, installed []string, fail bool) *[]string {
+	t.Helper()
+
+	origCommand, origLookPath := command, lookPath
+	t.Cleanup(func() { command, lookPath = origCommand, origLookPath })
+
+	var calls []string
+
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	command = func(exe string, args ...string) *exec.Cmd {
+		calls = append(calls, filepath.Base(exe)+" "+strings.Join(args, " "))
+
+		cmd := exec.Command(os.Args[0], "-test.run=TestSpeakerProcess")
+		cmd.Env = []string{"GO_WANT_SPEAKER_PROCESS=1"}
+		if fail {
+			cmd.Env = append(cmd.Env, "SPEAKER_FAIL=1")
+		}
+
+		return cmd
+	}
+
+	return &calls
+}
+
+func TestSpeakerProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_SPEAKER_PROCESS") != "1" {
+		return
+	}
+
+	if os.Getenv("SPEAKER_FAIL") == "1" {
+		os.Stderr.WriteString("no audio device")
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+func TestSpeech(t *testing.T) {
+	finished := New("Pomodoro", "Pomodoro finished!", SeverityNormal).WithKind("finished",
+		map[string]string{"Category": "Pomodoro", "Next": "short break", "NextMinutes": "5"})
+
+	testCases := []struct {
+		name      string
+		program   string
+		n         *Notify
+		installed []string
+		fail      bool
+		expCalls  []string
+		expErr    string
+	}{
+		{name: "Template", n: finished, installed: []string{"espeak-ng", "say"},
+			expCalls: []string{"espeak-ng Pomodoro done, take a 5 minute short break"}},
+		{name: "Wait", n: New("Pomodoro", "Take a break", SeverityNormal),
+			installed: []string{"spd-say", "espeak-ng"},
+			expCalls:  []string{"spd-say --wait Take a break"}},
+		{name: "Program", program: "espeak", n: New("Pomodoro", "Halfway", SeverityLow),
+			installed: []string{"spd-say", "espeak"},
+			expCalls:  []string{"espeak Halfway"}},
+		{name: "NotInstalled", program: "espeak", n: finished, installed: []string{"spd-say"},
+			expErr: ErrNoSpeaker.Error()},
+		{name: "NoSpeaker", n: finished, expErr: ErrNoSpeaker.Error()},
+		{name: "Fails", n: New("Pomodoro", "Take a break", SeverityNormal),
+			installed: []string{"say"}, fail: true,
+			expCalls: []string{"say Take a break"},
+			expErr:   "say: exit status 1: no audio device"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := mockSpeakers(t, tc.installed, tc.fail)
+
+			s, err := NewSpeech(tc.program)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.Send(tc.n)
+			if tc.expErr == "" && err != nil {
+				t.Fatalf("Expected no error, got %q instead\n", err)
+			}
+			if tc.expErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expErr)) {
+				t.Errorf("Expected error containing %q, got %v instead\n", tc.expErr, err)
+			}
+
+			if strings.Join(*calls, "\n") != strings.Join(tc.expCalls, "\n") {
+				t.Errorf("Expected calls %q, got %q instead\n", tc.expCalls, *calls)
+			}
+		})
+	}
+}
+
+func TestSpeechTemplates(t *testing.T) {
+	defer func(orig map[string]string) { SpeechTemplates = orig }(SpeechTemplates)
+
+	SpeechTemplates = map[string]string{"idle": "{{.Title}}: idle for {{.Minutes}} minutes"}
+
+	s, err := NewSpeech("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := New("Pomodoro", "Nothing running", SeverityUrgent).WithKind("idle", map[string]string{"Minutes": "20"})
+	text, err := s.Text(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "Pomodoro: idle for 20 minutes"
+	if text != exp {
+		t.Errorf("Expected %q, got %q instead\n", exp, text)
+	}
+
+	SpeechTemplates = map[string]string{"idle": "{{.Minutes"}
+	if _, err := NewSpeech(""); err == nil {
+		t.Error("Expected an error for a broken template")
+	}
+}
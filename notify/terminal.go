@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Modes of the terminal notifier.
+const (
+	// ModeOSC9 is the notification sequence of iTerm2, kitty, WezTerm,
+	// Windows Terminal and others.
+	ModeOSC9 = "9"
+	// ModeOSC777 is the notification sequence of rxvt-unicode, foot,
+	// Ghostty and VTE terminals such as GNOME Terminal.
+	ModeOSC777 = "777"
+	// ModeBell only rings the bell.
+	ModeBell = "bell"
+)
+
+var ErrNoTerminal = errors.New("No controlling terminal")
+
+// Terminal writes notifications as escape sequences to the controlling
+// terminal, so that they reach the desktop over SSH and from containers.
+// Every notification also rings the bell, for terminals that ignore the
+// sequence.
+type Terminal struct {
+	Mode string
+	// Tmux wraps the sequence so that tmux passes it to the terminal
+	// outside.
+	Tmux bool
+
+	open func() (io.WriteCloser, error)
+}
+
+// NewTerminal returns a notifier writing to the controlling terminal. An
+// empty mode is detected from the environment.
+func NewTerminal(mode string) (*Terminal, error) {
+	if mode == "" {
+		mode = detectMode(os.Getenv)
+	}
+
+	switch mode {
+	case ModeOSC9, ModeOSC777, ModeBell:
+	default:
+		return nil, fmt.Errorf("terminal: unknown mode %q, expected %s, %s or %s",
+			mode, ModeOSC9, ModeOSC777, ModeBell)
+	}
+
+	return &Terminal{
+		Mode: mode,
+		Tmux: os.Getenv("TMUX") != "",
+		open: openTTY,
+	}, nil
+}
+
+// openTTY opens the controlling terminal, even when stdout is redirected
+// or owned by the TUI.
+func openTTY() (io.WriteCloser, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+
+	return tty, nil
+}
+
+func (t *Terminal) Send(n *Notify) error {
+	w, err := t.open()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = io.WriteString(w, t.Sequence(n))
+
+	return err
+}
+
+// Sequence returns the bytes written for n.
+func (t *Terminal) Sequence(n *Notify) string {
+	var seq string
+
+	switch t.Mode {
+	case ModeOSC9:
+		seq = "\x1b]9;" + sanitize(n.title+": "+n.message) + "\a"
+	case ModeOSC777:
+		title := strings.ReplaceAll(sanitize(n.title), ";", ",")
+		seq = "\x1b]777;notify;" + title + ";" + sanitize(n.message) + "\a"
+	}
+
+	if t.Tmux && seq != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+
+	return seq + "\a"
+}
+
+// sanitize drops control characters, which would end the sequence early.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// detectMode picks the OSC sequence understood by the terminal in use.
+func detectMode(getenv func(string) string) string {
+	term := getenv("TERM")
+
+	switch {
+	case getenv("VTE_VERSION") != "",
+		getenv("TERM_PROGRAM") == "ghostty",
+		strings.HasPrefix(term, "rxvt"),
+		strings.HasPrefix(term, "foot"):
+		return ModeOSC777
+	default:
+		return ModeOSC9
+	}
+}
+
+// headless reports whether there is no desktop session to notify, as over
+// SSH or in a container.
+func headless(getenv func(string) string) bool {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		return false
+	}
+
+	return getenv("DISPLAY") == "" &&
+		getenv("WAYLAND_DISPLAY") == "" &&
+		getenv("DBUS_SESSION_BUS_ADDRESS") == ""
+}
+
+// openAuto opens the desktop notifier, or the terminal one when there is
+// no desktop session.
+func openAuto(string) (Notifier, error) {
+	if headless(os.Getenv) {
+		return NewTerminal("")
+	}
+
+	return desktop{}, nil
+}
@@ -0,0 +1,119 @@
+//go:build !integration
+// +build !integration
+
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+)
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestTerminal(t *testing.T) {
+	testCases := []struct {
+		name  string
+		mode  string
+		tmux  bool
+		title string
+		msg   string
+		exp   string
+	}{
+		{name: "OSC9", mode: ModeOSC9, title: "Pomodoro", msg: "Take a break",
+			exp: "\x1b]9;Pomodoro: Take a break\a\a"},
+		{name: "OSC777", mode: ModeOSC777, title: "Pomodoro", msg: "Take a break",
+			exp: "\x1b]777;notify;Pomodoro;Take a break\a\a"},
+		{name: "Bell", mode: ModeBell, title: "Pomodoro", msg: "Take a break",
+			exp: "\a"},
+		{name: "Tmux", mode: ModeOSC9, tmux: true, title: "Pomodoro", msg: "Done",
+			exp: "\x1bPtmux;\x1b\x1b]9;Pomodoro: Done\a\x1b\\\a"},
+		{name: "TmuxBell", mode: ModeBell, tmux: true, title: "Pomodoro", msg: "Done",
+			exp: "\a"},
+		{name: "Sanitized", mode: ModeOSC777, title: "a;b\x1b", msg: "line\nbreak\a",
+			exp: "\x1b]777;notify;a,b;linebreak\a\a"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			term := &Terminal{
+				Mode: tc.mode,
+				Tmux: tc.tmux,
+				open: func() (io.WriteCloser, error) { return nopCloser{&buf}, nil },
+			}
+
+			if err := term.Send(New(tc.title, tc.msg, SeverityNormal)); err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != tc.exp {
+				t.Errorf("Expected %q, got %q instead\n", tc.exp, buf.String())
+			}
+		})
+	}
+}
+
+func TestTerminalNoTTY(t *testing.T) {
+	term := &Terminal{
+		Mode: ModeOSC9,
+		open: func() (io.WriteCloser, error) { return nil, ErrNoTerminal },
+	}
+
+	err := term.Send(New("Pomodoro", "msg", SeverityNormal))
+	if !errors.Is(err, ErrNoTerminal) {
+		t.Errorf("Expected error %q, got %v instead\n", ErrNoTerminal, err)
+	}
+
+	if _, err := NewTerminal("99"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name        string
+		env         map[string]string
+		expMode     string
+		expHeadless bool
+	}{
+		{name: "SSH", env: map[string]string{"TERM": "xterm-256color"},
+			expMode: ModeOSC9, expHeadless: true},
+		{name: "X11", env: map[string]string{"TERM": "xterm-kitty", "DISPLAY": ":0"},
+			expMode: ModeOSC9},
+		{name: "Wayland", env: map[string]string{"TERM": "foot", "WAYLAND_DISPLAY": "wayland-1"},
+			expMode: ModeOSC777},
+		{name: "Bus", env: map[string]string{"DBUS_SESSION_BUS_ADDRESS": "unix:path=/run/bus"},
+			expMode: ModeOSC9},
+		{name: "VTE", env: map[string]string{"VTE_VERSION": "7600"},
+			expMode: ModeOSC777, expHeadless: true},
+		{name: "Urxvt", env: map[string]string{"TERM": "rxvt-unicode-256color"},
+			expMode: ModeOSC777, expHeadless: true},
+		{name: "Ghostty", env: map[string]string{"TERM_PROGRAM": "ghostty"},
+			expMode: ModeOSC777, expHeadless: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+
+			if mode := detectMode(getenv); mode != tc.expMode {
+				t.Errorf("Expected mode %q, got %q instead\n", tc.expMode, mode)
+			}
+
+			// A desktop is always there on macOS and Windows.
+			if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+				tc.expHeadless = false
+			}
+
+			if h := headless(getenv); h != tc.expHeadless {
+				t.Errorf("Expected headless %t, got %t instead\n", tc.expHeadless, h)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+// Package alert turns the events of a timer into notifications and sounds:
+// the start and end of intervals, milestones and idle reminders.
+//
+// It follows the runner of the process ticking the interval, which is the
+// daemon when one is running, so the alerts arrive once whether or not a
+// TUI is open, and also from a daemon or a foreground start on their own.
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/sound"
+	"github.com/ZeroBl21/z-timer/notify"
+)
+
+// Keys of the actions on the notifications. Start is offered by the idle
+// reminders as well as by the end of an interval.
+const (
+	ActionStart  = "start"
+	ActionSkip   = "skip"
+	ActionSnooze = "snooze"
+)
+
+// snoozeDelay is how long "+5 min" postpones the reminder that an interval
+// finished.
+const snoozeDelay = 5 * time.Minute
+
+// Alerts sends the notifications and plays the sounds for the events of a
+// controller. Failures are written to its logger.
+type Alerts struct {
+	config *pomodoro.IntervalConfig
+	n      notify.Notifier
+	snd    *sound.Player
+	log    *log.Logger
+	wg     sync.WaitGroup
+
+	// ctl is set by Follow, for the actions of the notifications.
+	ctl pomodoro.Controller
+
+	mu     sync.Mutex
+	snooze *time.Timer
+}
+
+// New returns alerts sent through n. config tells the interval that comes
+// next, and may be nil. Sounds are not played when snd is nil.
+func New(
+	config *pomodoro.IntervalConfig,
+	n notify.Notifier,
+	snd *sound.Player,
+	logOut io.Writer,
+) *Alerts {
+	return &Alerts{
+		config: config,
+		n:      n,
+		snd:    snd,
+		log:    log.New(logOut, "alert: ", log.LstdFlags),
+	}
+}
+
+// Follow sends the alerts for the events of ctl until the subscription
+// ends. The actions of the notifications act on ctl.
+func (a *Alerts) Follow(ctl pomodoro.Controller) error {
+	events, _, err := ctl.Subscribe()
+	if err != nil {
+		return err
+	}
+
+	a.ctl = ctl
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		for e := range events {
+			a.Dispatch(e)
+		}
+
+		// Nothing acts on the timer once it is gone.
+		a.cancelSnooze()
+	}()
+
+	return nil
+}
+
+// Dispatch sends the alert for e, if it has one, and returns without
+// waiting for it.
+func (a *Alerts) Dispatch(e pomodoro.Event) {
+	switch {
+	case e.Type == pomodoro.EventStarted:
+		a.started(e.Interval)
+	case e.Type == pomodoro.EventDone:
+		a.finished(e.Interval)
+	case e.Type == pomodoro.EventCancelled:
+		a.cancelSnooze()
+	case e.Type == pomodoro.EventMilestone && e.Milestone != nil:
+		a.milestone(e.Interval, *e.Milestone)
+	case e.Type == pomodoro.EventIdle && e.Idle != nil:
+		a.idle(e.Idle)
+	}
+}
+
+// Wait waits for the alerts still being sent or played.
+func (a *Alerts) Wait() {
+	a.wg.Wait()
+}
+
+// started announces the interval i that started.
+func (a *Alerts) started(i pomodoro.Interval) {
+	a.cancelSnooze()
+
+	msg := "Take a break"
+	if i.Category == pomodoro.CategoryPomodoro {
+		msg = "Focus on your task"
+	}
+
+	n := notify.New("Pomodoro", msg, notify.SeverityNormal).WithKind("started", map[string]string{
+		"Category": Spoken(i.Category),
+		"Minutes":  Minutes(i.PlannedDuration),
+	})
+
+	a.send(n)
+	a.play(sound.CueStart)
+}
+
+// finished announces the finished interval i with actions to start or skip
+// the next interval, or to be reminded again later. Backends without
+// actions show the message alone.
+func (a *Alerts) finished(i pomodoro.Interval) {
+	a.cancelSnooze()
+
+	if i.Category == pomodoro.CategoryPomodoro {
+		a.play(sound.CueEnd)
+	} else {
+		a.play(sound.CueBreakOver)
+	}
+
+	a.send(a.finishedNotify(i))
+}
+
+func (a *Alerts) finishedNotify(i pomodoro.Interval) *notify.Notify {
+	msg := fmt.Sprintf("%s finished!", i.Category)
+	if i.Remaining() > 0 {
+		msg = fmt.Sprintf("%s ended early!", i.Category)
+	}
+
+	next := "Start pomodoro"
+	if i.Category == pomodoro.CategoryPomodoro {
+		next = "Start break"
+	}
+
+	data := map[string]string{
+		"Category": Spoken(i.Category),
+		"Minutes":  Minutes(i.ActualDuration),
+	}
+	if a.config != nil {
+		if category, err := pomodoro.NextCategory(a.config); err == nil {
+			data["Next"] = Spoken(category)
+			data["NextMinutes"] = Minutes(a.config.Duration(category))
+		}
+	}
+
+	return notify.New("Pomodoro", msg, notify.SeverityNormal).WithActions(
+		func(key string) { a.act(key, i) },
+		notify.Action{Key: ActionStart, Label: next},
+		notify.Action{Key: ActionSkip, Label: "Skip"},
+		notify.Action{Key: ActionSnooze, Label: "+5 min"},
+	).WithKind("finished", data)
+}
+
+// milestone announces that i reached m, below the severity of the end of
+// an interval.
+func (a *Alerts) milestone(i pomodoro.Interval, m pomodoro.Milestone) {
+	n := notify.New("Pomodoro", m.Message, notify.SeverityLow).WithKind("milestone", map[string]string{
+		"Category":  Spoken(i.Category),
+		"Remaining": Minutes(i.Remaining()),
+	})
+
+	a.send(n)
+
+	if m.Sound != "" {
+		a.play(m.Sound)
+	}
+}
+
+// idle reminds that nothing ran since idle.Since, more urgently with every
+// reminder.
+func (a *Alerts) idle(idle *pomodoro.Idle) {
+	severity := notify.SeverityLow
+	switch {
+	case idle.Reminders >= 3:
+		severity = notify.SeverityUrgent
+	case idle.Reminders == 2:
+		severity = notify.SeverityNormal
+	}
+
+	idleFor := time.Since(idle.Since)
+
+	n := notify.New("Pomodoro", IdleMessage(idleFor), severity).WithActions(
+		func(key string) { a.act(key, pomodoro.Interval{}) },
+		notify.Action{Key: ActionStart, Label: "Start"},
+	).WithKind("idle", map[string]string{"Minutes": Minutes(idleFor)})
+
+	a.send(n)
+}
+
+// IdleMessage tells for how long nothing ran.
+func IdleMessage(idleFor time.Duration) string {
+	return fmt.Sprintf("Nothing running for %s", idleFor.Round(time.Minute))
+}
+
+// act runs the action clicked on a notification. Snoozing sends the
+// notification of the finished interval i again later.
+func (a *Alerts) act(key string, i pomodoro.Interval) {
+	if a.ctl == nil {
+		return
+	}
+
+	var err error
+
+	switch key {
+	case ActionStart:
+		_, err = a.ctl.Start()
+	case ActionSkip:
+		_, err = a.ctl.Skip()
+	case ActionSnooze:
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		if a.snooze != nil {
+			a.snooze.Stop()
+		}
+		a.snooze = time.AfterFunc(snoozeDelay, func() { a.send(a.finishedNotify(i)) })
+		return
+	}
+
+	// Already done from the TUI or another client.
+	if errors.Is(err, pomodoro.ErrInvalidState) || errors.Is(err, pomodoro.ErrIntervalCompleted) {
+		return
+	}
+	if err != nil {
+		a.log.Printf("%s: %v", key, err)
+	}
+}
+
+// cancelSnooze drops a pending reminder, as the timer moved on.
+func (a *Alerts) cancelSnooze() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.snooze != nil {
+		a.snooze.Stop()
+		a.snooze = nil
+	}
+}
+
+func (a *Alerts) send(n *notify.Notify) {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		if err := a.n.Send(n); err != nil {
+			a.log.Printf("%s notification: %v", n.Kind(), err)
+		}
+	}()
+}
+
+func (a *Alerts) play(cue string) {
+	if a.snd == nil {
+		return
+	}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		if err := a.snd.Play(cue); err != nil {
+			a.log.Printf("%s sound: %v", cue, err)
+		}
+	}()
+}
+
+// Spoken returns category the way it is said.
+func Spoken(category string) string {
+	switch category {
+	case pomodoro.CategoryShortBreak:
+		return "short break"
+	case pomodoro.CategoryLongBreak:
+		return "long break"
+	}
+
+	return strings.ToLower(category)
+}
+
+// Minutes returns d in whole minutes.
+func Minutes(d time.Duration) string {
+	return strconv.Itoa(int(d.Round(time.Minute).Minutes()))
+}
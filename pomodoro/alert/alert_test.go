@@ -0,0 +1,183 @@
+package alert_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/alert"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+	"github.com/ZeroBl21/z-timer/notify"
+)
+
+// recorder keeps the notifications sent to it, failing when err is set.
+type recorder struct {
+	mu   sync.Mutex
+	sent []*notify.Notify
+	err  error
+}
+
+func (r *recorder) Send(n *notify.Notify) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sent = append(r.sent, n)
+
+	return r.err
+}
+
+func testEvent(typ string) pomodoro.Event {
+	return pomodoro.Event{
+		Type: typ,
+		Time: time.Now(),
+		Interval: pomodoro.Interval{
+			ID:              3,
+			PlannedDuration: 25 * time.Minute,
+			ActualDuration:  24 * time.Minute,
+			Category:        pomodoro.CategoryShortBreak,
+			State:           pomodoro.StateRunning,
+		},
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	milestone := testEvent(pomodoro.EventMilestone)
+	milestone.Milestone = &pomodoro.Milestone{Message: "1 minute left"}
+
+	idle := func(reminders int) pomodoro.Event {
+		e := testEvent(pomodoro.EventIdle)
+		e.Idle = &pomodoro.Idle{Since: time.Now().Add(-20 * time.Minute), Reminders: reminders}
+		return e
+	}
+
+	testCases := []struct {
+		name        string
+		event       pomodoro.Event
+		expKind     string
+		expSeverity notify.Severity
+		expMessage  string
+		expData     map[string]string
+	}{
+		{name: "Started", event: testEvent(pomodoro.EventStarted), expKind: "started", expSeverity: notify.SeverityNormal,
+			expMessage: "Take a break", expData: map[string]string{"Category": "short break", "Minutes": "25"}},
+		{name: "Finished", event: testEvent(pomodoro.EventDone), expKind: "finished", expSeverity: notify.SeverityNormal,
+			expMessage: "ShortBreak ended early!", expData: map[string]string{"Category": "short break", "Minutes": "24"}},
+		{name: "Milestone", event: milestone, expKind: "milestone", expSeverity: notify.SeverityLow,
+			expMessage: "1 minute left", expData: map[string]string{"Category": "short break", "Remaining": "1"}},
+		{name: "FirstIdle", event: idle(1), expKind: "idle", expSeverity: notify.SeverityLow,
+			expMessage: "Nothing running for 20m0s", expData: map[string]string{"Minutes": "20"}},
+		{name: "SecondIdle", event: idle(2), expKind: "idle", expSeverity: notify.SeverityNormal,
+			expMessage: "Nothing running for 20m0s", expData: map[string]string{"Minutes": "20"}},
+		{name: "LaterIdle", event: idle(5), expKind: "idle", expSeverity: notify.SeverityUrgent,
+			expMessage: "Nothing running for 20m0s", expData: map[string]string{"Minutes": "20"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var rec recorder
+			a := alert.New(nil, &rec, nil, &bytes.Buffer{})
+
+			a.Dispatch(tc.event)
+			a.Wait()
+
+			if len(rec.sent) != 1 {
+				t.Fatalf("Expected 1 notification, got %d instead.\n", len(rec.sent))
+			}
+			n := rec.sent[0]
+
+			if n.Kind() != tc.expKind {
+				t.Errorf("Expected kind %q, got %q instead.\n", tc.expKind, n.Kind())
+			}
+			if n.Severity() != tc.expSeverity {
+				t.Errorf("Expected severity %q, got %q instead.\n", tc.expSeverity, n.Severity())
+			}
+			if n.Message() != tc.expMessage {
+				t.Errorf("Expected message %q, got %q instead.\n", tc.expMessage, n.Message())
+			}
+			for k, v := range tc.expData {
+				if n.Data()[k] != v {
+					t.Errorf("Expected %s %q, got %q instead.\n", k, v, n.Data()[k])
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchOther(t *testing.T) {
+	var rec recorder
+	a := alert.New(nil, &rec, nil, &bytes.Buffer{})
+
+	for _, typ := range []string{pomodoro.EventTick, pomodoro.EventPaused, pomodoro.EventCancelled} {
+		a.Dispatch(testEvent(typ))
+	}
+	a.Wait()
+
+	if len(rec.sent) != 0 {
+		t.Errorf("Expected no notifications, got %d instead.\n", len(rec.sent))
+	}
+}
+
+func TestFollow(t *testing.T) {
+	const duration = 2 * time.Second
+
+	config := pomodoro.NewConfig(repository.NewInMemoryRepo(), duration, duration, duration)
+	r := pomodoro.NewRunner(config)
+
+	if err := r.SetMilestones([]pomodoro.Milestone{{Progress: 0.5, Message: "Halfway"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var log bytes.Buffer
+	rec := recorder{err: errors.New("no server")}
+	a := alert.New(config, &rec, nil, &log)
+
+	if err := a.Follow(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interval starts, reaches the milestone and finishes.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec.mu.Lock()
+		n := len(rec.sent)
+		rec.mu.Unlock()
+
+		if n >= 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the notifications")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	a.Wait()
+
+	kinds := map[string]*notify.Notify{}
+	for _, n := range rec.sent {
+		kinds[n.Kind()] = n
+	}
+
+	if n := kinds["milestone"]; n == nil || n.Message() != "Halfway" {
+		t.Errorf("Expected milestone %q, got %v instead.\n", "Halfway", n)
+	}
+
+	if n := kinds["finished"]; n == nil || n.Data()["Next"] != "short break" {
+		t.Errorf("Expected a finished notification with the next break, got %v instead.\n", n)
+	}
+
+	if !strings.Contains(log.String(), "no server") {
+		t.Errorf("Expected the failure in the log, got %q instead.\n", log.String())
+	}
+}
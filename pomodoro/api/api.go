@@ -0,0 +1,233 @@
+// Package api serves a pomodoro.Controller over HTTP for editor plugins and
+// dashboards.
+//
+//	GET  /v1/interval                current interval
+//	POST /v1/interval/{action}       start, resume, pause, end, skip or
+//	                                 extend, with {"seconds": n} for extend
+//	GET  /v1/intervals?from=&to=     history, RFC 3339 or YYYY-MM-DD bounds
+//	GET  /v1/events                  Server-Sent Events stream
+//	GET  /openapi.json               OpenAPI description
+//
+// Every endpoint but /openapi.json requires the token, either as
+// "Authorization: Bearer <token>" or, for EventSource clients that cannot
+// set headers, as the token query parameter.
+package api
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// keepAlive is how often an idle event stream gets a comment line, so that
+// proxies and clients do not time it out.
+const keepAlive = 15 * time.Second
+
+type handler struct {
+	ctl    pomodoro.Controller
+	config *pomodoro.IntervalConfig
+	token  string
+}
+
+// NewHandler returns the API for ctl. History is read from config.
+func NewHandler(ctl pomodoro.Controller, config *pomodoro.IntervalConfig, token string) http.Handler {
+	h := &handler{ctl: ctl, config: config, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", h.openAPI)
+	mux.Handle("GET /v1/interval", h.auth(h.status))
+	mux.Handle("POST /v1/interval/{action}", h.auth(h.action))
+	mux.Handle("GET /v1/intervals", h.auth(h.history))
+	mux.Handle("GET /v1/events", h.auth(h.events))
+
+	return mux
+}
+
+func (h *handler) auth(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.URL.Query().Get("token")
+		}
+
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("Invalid or missing token"))
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+func (h *handler) openAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPI)
+}
+
+func (h *handler) status(w http.ResponseWriter, r *http.Request) {
+	i, err := h.ctl.Status()
+	writeInterval(w, i, err)
+}
+
+func (h *handler) action(w http.ResponseWriter, r *http.Request) {
+	var (
+		i   pomodoro.Interval
+		err error
+	)
+
+	switch r.PathValue("action") {
+	case "start":
+		i, err = h.ctl.Start()
+	case "resume":
+		i, err = h.ctl.Resume()
+	case "pause":
+		i, err = h.ctl.Pause()
+	case "end":
+		i, err = h.ctl.End()
+	case "skip":
+		i, err = h.ctl.Skip()
+	case "extend":
+		var body struct {
+			Seconds float64 `json:"seconds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid body: %w", err))
+			return
+		}
+		i, err = h.ctl.Extend(time.Duration(body.Seconds * float64(time.Second)))
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("Unknown action %q", r.PathValue("action")))
+		return
+	}
+
+	writeInterval(w, i, err)
+}
+
+func (h *handler) history(w http.ResponseWriter, r *http.Request) {
+	from, err := parseBound(r.URL.Query().Get("from"), time.Time{})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid from: %w", err))
+		return
+	}
+
+	to, err := parseBound(r.URL.Query().Get("to"), time.Now().Add(time.Second))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("Invalid to: %w", err))
+		return
+	}
+
+	intervals, err := pomodoro.History(h.config, from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pomodoro.IntervalsJSON(intervals))
+}
+
+// events streams the controller's events as Server-Sent Events, with the
+// event type as the SSE event name and its sequence number as the id.
+func (h *handler) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("Streaming unsupported"))
+		return
+	}
+
+	events, cancel, err := h.ctl.Subscribe()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(pomodoro.NewEventJSON(e))
+			if err != nil {
+				return
+			}
+
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data)
+			flusher.Flush()
+
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// parseBound parses an RFC 3339 time or a local YYYY-MM-DD day.
+func parseBound(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+func writeInterval(w http.ResponseWriter, i pomodoro.Interval, err error) {
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, pomodoro.NewIntervalJSON(i))
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, pomodoro.ErrNoInterval):
+		return http.StatusNotFound
+	case errors.Is(err, pomodoro.ErrIntervalNotRunning),
+		errors.Is(err, pomodoro.ErrIntervalCompleted),
+		errors.Is(err, pomodoro.ErrInvalidState):
+		return http.StatusConflict
+	case errors.Is(err, pomodoro.ErrInvalidDuration):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,212 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/api"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+const token = "secret"
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	repo, err := repository.Open("sqlite:" + filepath.Join(t.TempDir(), "pomo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const duration = 2 * time.Second
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	runner := pomodoro.NewRunner(config)
+
+	srv := httptest.NewServer(api.NewHandler(runner, config, token))
+	t.Cleanup(func() {
+		runner.Close()
+		srv.Close()
+	})
+
+	return srv
+}
+
+func request(t *testing.T, srv *httptest.Server, method, path, body string) (int, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var v map[string]any
+	json.NewDecoder(resp.Body).Decode(&v)
+
+	return resp.StatusCode, v
+}
+
+func TestAuth(t *testing.T) {
+	srv := newServer(t)
+
+	testCases := []struct {
+		name   string
+		path   string
+		header string
+		exp    int
+	}{
+		{name: "Missing", path: "/v1/interval", exp: http.StatusUnauthorized},
+		{name: "Wrong", path: "/v1/interval", header: "Bearer nope", exp: http.StatusUnauthorized},
+		{name: "Header", path: "/v1/interval", header: "Bearer " + token, exp: http.StatusNotFound},
+		{name: "Query", path: "/v1/interval?token=" + token, exp: http.StatusNotFound},
+		{name: "OpenAPI", path: "/openapi.json", exp: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.exp {
+				t.Errorf("Expected status %d, got %d instead.\n", tt.exp, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	srv := newServer(t)
+
+	testCases := []struct {
+		method   string
+		path     string
+		body     string
+		expCode  int
+		expState string
+	}{
+		{http.MethodPost, "/v1/interval/pause", "", http.StatusConflict, ""},
+		{http.MethodPost, "/v1/interval/start", "", http.StatusOK, "running"},
+		{http.MethodPost, "/v1/interval/extend", `{"seconds": 60}`, http.StatusOK, "running"},
+		{http.MethodPost, "/v1/interval/extend", `{"seconds": -1}`, http.StatusBadRequest, ""},
+		{http.MethodPost, "/v1/interval/pause", "", http.StatusOK, "paused"},
+		{http.MethodGet, "/v1/interval", "", http.StatusOK, "paused"},
+		{http.MethodPost, "/v1/interval/end", "", http.StatusConflict, ""},
+		{http.MethodPost, "/v1/interval/resume", "", http.StatusOK, "running"},
+		{http.MethodPost, "/v1/interval/end", "", http.StatusOK, "done"},
+		{http.MethodPost, "/v1/interval/nope", "", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range testCases {
+		code, v := request(t, srv, tt.method, tt.path, tt.body)
+
+		if code != tt.expCode {
+			t.Fatalf("%s %s: expected status %d, got %d (%v) instead.\n",
+				tt.method, tt.path, tt.expCode, code, v)
+		}
+
+		if tt.expState != "" && v["state"] != tt.expState {
+			t.Errorf("%s %s: expected state %q, got %v instead.\n",
+				tt.method, tt.path, tt.expState, v["state"])
+		}
+
+		if tt.path == "/v1/interval/extend" && code == http.StatusOK && v["planned_seconds"] != 62.0 {
+			t.Errorf("Expected 62 planned seconds after extending, got %v.\n", v["planned_seconds"])
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/intervals?from=2000-01-01", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var history []pomodoro.IntervalJSON
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history) != 1 || history[0].State != "done" {
+		t.Errorf("Expected the finished interval in the history, got %+v.\n", history)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	srv := newServer(t)
+
+	resp, err := srv.Client().Get(srv.URL + "/v1/events?token=" + token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q.\n", ct)
+	}
+
+	if code, v := request(t, srv, http.MethodPost, "/v1/interval/start", ""); code != http.StatusOK {
+		t.Fatalf("Expected start to succeed, got %d (%v).\n", code, v)
+	}
+
+	lines := make(chan string)
+	go func() {
+		s := bufio.NewScanner(resp.Body)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+		close(lines)
+	}()
+
+	var got []string
+	timeout := time.After(5 * time.Second)
+
+	for len(got) == 0 || got[len(got)-1] != "event: done" {
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				t.Fatalf("Stream ended early: %v", got)
+			}
+			if strings.HasPrefix(l, "event: ") {
+				got = append(got, l)
+			}
+			if data, ok := strings.CutPrefix(l, "data: "); ok {
+				var e pomodoro.EventJSON
+				if err := json.Unmarshal([]byte(data), &e); err != nil {
+					t.Fatalf("Invalid event data %q: %s", data, err)
+				}
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for the done event, got %v", got)
+		}
+	}
+
+	if got[0] != "event: started" || len(got) < 3 {
+		t.Errorf("Expected started, ticks and done, got %v instead.\n", got)
+	}
+}
@@ -3,9 +3,11 @@ package app
 import (
 	"context"
 	"image"
-	"time"
+	"slices"
+	"sync/atomic"
 
 	"github.com/mum4k/termdash"
+	"github.com/mum4k/termdash/container"
 	"github.com/mum4k/termdash/terminal/tcell"
 	"github.com/mum4k/termdash/terminal/terminalapi"
 
@@ -16,75 +18,175 @@ type App struct {
 	ctx        context.Context
 	controller *termdash.Controller
 	term       *tcell.Terminal
+	root       *container.Container
 	size       image.Point
 
+	layouts *layouts
+	// pinned is the layout chosen with the layout key or the options, and
+	// layout the one shown, which the keyboard subscriber reads.
+	pinned string
+	layout atomic.Value
+
 	redrawCh chan bool
 	errCh    chan error
+	resizeCh chan struct{}
+	toggleCh chan struct{}
+}
+
+// Options customizes the TUI. Notifications and sounds belong to the
+// process ticking the timer, so they are not set here.
+type Options struct {
+	// Keymap binds the actions of the TUI to keys, or DefaultKeymap when
+	// nil.
+	Keymap Keymap
+	// Theme colors the TUI, or is the DefaultTheme when nil.
+	Theme *Theme
+	// Layout is one of Layouts, or LayoutAuto when empty to fit the
+	// terminal.
+	Layout string
 }
 
-func New(config *pomodoro.IntervalConfig) (*App, error) {
+// New builds the TUI. ctl drives the timer, while config is used to read
+// the history shown in the summaries.
+func New(
+	config *pomodoro.IntervalConfig,
+	ctl pomodoro.Controller,
+	opts Options,
+) (*App, error) {
+	if opts.Keymap == nil {
+		opts.Keymap = DefaultKeymap()
+	}
+
+	if opts.Theme == nil {
+		t := Themes[DefaultTheme]
+		opts.Theme = &t
+	}
+	if opts.Layout == "" {
+		opts.Layout = LayoutAuto
+	}
+
+	if err := ValidateLayout(opts.Layout); err != nil {
+		return nil, err
+	}
+
+	keys, err := opts.Keymap.bindings()
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
-	quitter := func(k *terminalapi.Keyboard) {
-		if k.Key == 'q' || k.Key == 'Q' {
+	redrawCh := make(chan bool)
+	errCh := make(chan error)
+
+	a := &App{
+		ctx:    ctx,
+		pinned: opts.Layout,
+
+		redrawCh: redrawCh,
+		errCh:    errCh,
+		resizeCh: make(chan struct{}, 1),
+		toggleCh: make(chan struct{}, 1),
+	}
+
+	// Runs on the event loop of termdash, so it hands the layout changes to
+	// Run, which owns the controller.
+	keyboard := func(k *terminalapi.Keyboard) {
+		switch {
+		case slices.Contains(keys[ActionQuit], k.Key):
 			cancel()
+
+		case slices.Contains(keys[ActionLayout], k.Key):
+			select {
+			case a.toggleCh <- struct{}{}:
+			default:
+			}
+
+		// The buttons handle their keys while they are shown.
+		case a.layout.Load() == LayoutMinimal:
+			for action, run := range a.layouts.btnSet.actions {
+				if slices.Contains(keys[action], k.Key) {
+					run()
+				}
+			}
 		}
 	}
 
-	redrawCh := make(chan bool)
-	errCh := make(chan error)
+	wid, err := newWidgets(ctx, *opts.Theme, errCh)
+	if err != nil {
+		return nil, err
+	}
 
-	wid, err := newWidgets(ctx, errCh)
+	sum, err := newSummary(ctx, config, *opts.Theme, errCh)
 	if err != nil {
 		return nil, err
 	}
 
-	sum, err := newSummary(ctx, config, errCh)
+	btnSet, err := newButtonSet(ctl, wid, sum, opts.Keymap, *opts.Theme, keys, redrawCh, errCh)
 	if err != nil {
 		return nil, err
 	}
 
-	btnSet, err := newButtonSet(ctx, config, wid, sum, redrawCh, errCh)
+	go followEvents(ctx, ctl, wid, sum, redrawCh, errCh)
+	go watchChanges(ctx, config, wid, sum, redrawCh, errCh)
+
+	// A runner in this process reports ticking failures on the side.
+	if r, ok := ctl.(interface{ Errors() <-chan error }); ok {
+		go func() {
+			for {
+				select {
+				case err := <-r.Errors():
+					errCh <- err
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	a.layouts = &layouts{
+		btnSet: btnSet,
+		wid:    wid,
+		sum:    sum,
+		km:     opts.Keymap,
+		theme:  *opts.Theme,
+	}
+
+	a.term, err = tcell.New()
 	if err != nil {
 		return nil, err
 	}
 
-	term, err := tcell.New()
+	a.size = a.term.Size()
+	layout := a.fit()
+
+	layoutOpts, err := a.layouts.options(layout)
 	if err != nil {
 		return nil, err
 	}
 
-	container, err := newGrid(btnSet, wid, sum, term)
+	a.root, err = container.New(a.term, append(layoutOpts, container.ID(rootID))...)
 	if err != nil {
 		return nil, err
 	}
+	a.layout.Store(layout)
 
-	controller, err := termdash.NewController(
-		term,
-		container,
-		termdash.KeyboardSubscriber(quitter),
+	a.controller, err = termdash.NewController(
+		&resizeNotifier{Terminal: a.term, resized: a.resizeCh},
+		a.root,
+		termdash.KeyboardSubscriber(keyboard),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &App{
-		ctx:        ctx,
-		controller: controller,
-		term:       term,
-
-		redrawCh: redrawCh,
-		errCh:    errCh,
-	}, nil
+	return a, nil
 }
 
 func (a *App) Run() error {
 	defer a.term.Close()
 	defer a.controller.Close()
 
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
 	for {
 		select {
 		case <-a.redrawCh:
@@ -92,11 +194,17 @@ func (a *App) Run() error {
 				return err
 			}
 
-		case <-ticker.C:
+		case <-a.resizeCh:
 			if err := a.resize(); err != nil {
 				return err
 			}
 
+		case <-a.toggleCh:
+			a.pinned = nextLayout(a.pinned)
+			if err := a.setLayout(a.fit()); err != nil {
+				return err
+			}
+
 		case err := <-a.errCh:
 			if err != nil {
 				return err
@@ -114,6 +222,34 @@ func (a *App) resize() error {
 	}
 
 	a.size = a.term.Size()
+
+	return a.setLayout(a.fit())
+}
+
+// fit returns the pinned layout, or the one fitting the terminal when the
+// layout is automatic.
+func (a *App) fit() string {
+	if a.pinned == LayoutAuto {
+		return autoLayout(a.size)
+	}
+
+	return a.pinned
+}
+
+// setLayout lays the widgets out as layout and redraws the whole terminal.
+func (a *App) setLayout(layout string) error {
+	if layout != a.layout.Load() {
+		opts, err := a.layouts.options(layout)
+		if err != nil {
+			return err
+		}
+
+		if err := a.root.Update(rootID, opts...); err != nil {
+			return err
+		}
+		a.layout.Store(layout)
+	}
+
 	if err := a.term.Clear(); err != nil {
 		return err
 	}
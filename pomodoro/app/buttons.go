@@ -1,13 +1,10 @@
 package app
 
 import (
-	"context"
-	"fmt"
-	"os/exec"
-	"runtime"
+	"errors"
 
 	"github.com/ZeroBl21/go-ztimer/pomodoro"
-	"github.com/mum4k/termdash/cell"
+	"github.com/mum4k/termdash/keyboard"
 	"github.com/mum4k/termdash/widgets/button"
 )
 
@@ -15,128 +12,111 @@ type buttonSet struct {
 	btnStart *button.Button
 	btnPause *button.Button
 	btnEnd   *button.Button
+
+	// actions run the actions of the buttons, for the layouts without
+	// them.
+	actions map[string]func()
 }
 
 func newButtonSet(
-	ctx context.Context,
-	config *pomodoro.IntervalConfig,
+	ctl pomodoro.Controller,
 	wid *widgets,
 	sum *summary,
+	km Keymap,
+	theme Theme,
+	keys map[string][]keyboard.Key,
 	redrawCh chan<- bool,
 	errCh chan<- error,
 ) (*buttonSet, error) {
+	// Progress and completion are shown by followEvents.
 	startInterval := func() {
-		i, err := pomodoro.GetInterval(config)
-		errCh <- err
-
-		start := func(i pomodoro.Interval) {
-			msg := "Take a break"
-			if i.Category == pomodoro.CategoryPomodoro {
-				msg = "Focus on your task"
-			}
-
-			wid.update([]int{}, i.Category, msg, "", redrawCh)
-			send_notification(msg)
-		}
-
-		periodic := func(i pomodoro.Interval) {
-			wid.update(
-				[]int{int(i.ActualDuration), int(i.PlannedDuration)},
-				"", "", fmt.Sprint(i.PlannedDuration-i.ActualDuration), redrawCh)
-		}
-
-		end := func(i pomodoro.Interval) {
-			wid.update([]int{}, "", "Nothing running...", "", redrawCh)
-			sum.update(redrawCh)
-
-			// TODO: Make this cross plataform
-			if runtime.GOOS == "linux" {
-				cmd := exec.Command("paplay", "complete.oga")
-				cmd.Run()
-			}
-
-			msg := fmt.Sprintf("%s finished!", i.Category)
-			send_notification(msg)
+		if _, err := ctl.Start(); err != nil {
+			errCh <- err
 		}
-
-		errCh <- i.Start(ctx, config, start, periodic, end)
 	}
 
 	pauseInterval := func() {
-		i, err := pomodoro.GetInterval(config)
+		i, err := ctl.Pause()
 		if err != nil {
-			errCh <- err
-			return
-		}
-
-		if err := i.Pause(config); err != nil {
-			if err == pomodoro.ErrIntervalNotRunning {
+			if errors.Is(err, pomodoro.ErrIntervalNotRunning) {
 				return
 			}
 			errCh <- err
 			return
 		}
 
-		wid.update([]int{}, "", "Paused... press start to continue", "", redrawCh)
+		wid.update([]int{}, i.Category, "Paused... press start to continue", "", redrawCh)
 	}
 
 	endInterval := func() {
-		i, err := pomodoro.GetInterval(config)
-		if err != nil {
-			errCh <- err
-			return
-		}
-
-		if err := i.End(config); err != nil {
-			if err == pomodoro.ErrIntervalNotRunning {
+		if _, err := ctl.End(); err != nil {
+			if errors.Is(err, pomodoro.ErrIntervalNotRunning) {
 				return
 			}
 			errCh <- err
 			return
 		}
 
-		msg := fmt.Sprintf("%s ended early!", i.Category)
-		send_notification(msg)
-
 		wid.update([]int{}, "", "Nothing running...", "", redrawCh)
 		sum.update(redrawCh)
 	}
 
-	btnStart, err := button.New("(S)tart", func() error {
+	startLabel := km.label(ActionStart, "Start")
+	pauseLabel := km.label(ActionPause, "Pause")
+
+	// The start button takes the place of the pause button on small
+	// screens, so both are as wide.
+	widest := pauseLabel
+	if len(startLabel) > len(widest) {
+		widest = startLabel
+	}
+
+	btnStart, err := button.New(startLabel, func() error {
 		go startInterval()
 		return nil
 	},
-		button.GlobalKey('s'),
-		button.WidthFor("(P)ause"),
+		button.FillColor(theme.Start),
+		button.TextColor(theme.ButtonText),
+		button.GlobalKeys(keys[ActionStart]...),
+		button.WidthFor(widest),
 		button.Height(2),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	btnPause, err := button.New("(P)ause", func() error {
+	btnPause, err := button.New(pauseLabel, func() error {
 		go pauseInterval()
 		return nil
 	},
-		button.FillColor(cell.ColorNumber(220)),
-		button.GlobalKey('p'),
+		button.FillColor(theme.Pause),
+		button.TextColor(theme.ButtonText),
+		button.GlobalKeys(keys[ActionPause]...),
+		button.WidthFor(widest),
 		button.Height(2),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	btnEnd, err := button.New("(E)nd", func() error {
+	btnEnd, err := button.New(km.label(ActionEnd, "End"), func() error {
 		go endInterval()
 		return nil
 	},
-		button.FillColor(cell.ColorRed),
-		button.GlobalKey('e'),
+		button.FillColor(theme.End),
+		button.TextColor(theme.ButtonText),
+		button.GlobalKeys(keys[ActionEnd]...),
 		button.Height(2),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &buttonSet{btnStart, btnPause, btnEnd}, nil
+	actions := map[string]func(){
+		ActionStart: func() { go startInterval() },
+		ActionPause: func() { go pauseInterval() },
+		ActionEnd:   func() { go endInterval() },
+	}
+
+	return &buttonSet{btnStart, btnPause, btnEnd, actions}, nil
 }
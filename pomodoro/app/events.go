@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/alert"
+)
+
+// followEvents updates the widgets from the events of the controller,
+// whether the timer runs in this process or in the daemon.
+func followEvents(
+	ctx context.Context,
+	ctl pomodoro.Controller,
+	wid *widgets,
+	sum *summary,
+	redrawCh chan<- bool,
+	errCh chan<- error,
+) {
+	events, cancel, err := ctl.Subscribe()
+	if err != nil {
+		errCh <- err
+		return
+	}
+	defer cancel()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				if ctx.Err() == nil {
+					errCh <- errors.New("Lost connection to the timer")
+				}
+				return
+			}
+
+			showEvent(e, wid, sum, redrawCh)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// showEvent updates the widgets for e. The process ticking the timer sends
+// the notifications and plays the sounds.
+func showEvent(e pomodoro.Event, wid *widgets, sum *summary, redrawCh chan<- bool) {
+	i := e.Interval
+
+	switch e.Type {
+	case pomodoro.EventStarted:
+		msg := "Take a break"
+		if i.Category == pomodoro.CategoryPomodoro {
+			msg = "Focus on your task"
+		}
+
+		wid.update([]int{}, i.Category, msg, "", redrawCh)
+
+	case pomodoro.EventTick:
+		wid.update(
+			[]int{int(i.ActualDuration), int(i.PlannedDuration)},
+			"", "", fmt.Sprint(i.PlannedDuration-i.ActualDuration), redrawCh)
+
+	case pomodoro.EventPaused:
+		wid.update([]int{}, i.Category, "Paused... press start to continue", "", redrawCh)
+
+	case pomodoro.EventDone:
+		wid.update([]int{}, "", "Nothing running...", "", redrawCh)
+		sum.update(redrawCh)
+
+	case pomodoro.EventIdle:
+		if e.Idle != nil {
+			wid.update([]int{}, "", alert.IdleMessage(time.Since(e.Idle.Since))+"...", "", redrawCh)
+		}
+
+	case pomodoro.EventCancelled:
+		wid.update([]int{}, "", "Nothing running...", "", redrawCh)
+		sum.update(redrawCh)
+	}
+}
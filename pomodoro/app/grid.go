@@ -1,99 +1,125 @@
 package app
 
 import (
+	"fmt"
+
 	"github.com/mum4k/termdash/align"
 	"github.com/mum4k/termdash/container"
 	"github.com/mum4k/termdash/container/grid"
 	"github.com/mum4k/termdash/linestyle"
-	"github.com/mum4k/termdash/terminal/terminalapi"
 )
 
-func newGrid(
-	btnSet *buttonSet,
-	wid *widgets,
-	sum *summary,
-	term terminalapi.Terminal,
-) (*container.Container, error) {
+// rootID identifies the container holding the layout.
+const rootID = "root"
+
+// layouts arranges the widgets of the TUI in each of the Layouts.
+type layouts struct {
+	btnSet *buttonSet
+	wid    *widgets
+	sum    *summary
+	km     Keymap
+	theme  Theme
+}
+
+// options returns the options of the root container laying the widgets out
+// as layout.
+func (l *layouts) options(layout string) ([]container.Option, error) {
 	builder := grid.New()
 
-	// first row
-	builder.Add(
-		grid.RowHeightPerc(30,
-			// col one
-			grid.ColWidthPercWithOpts(30,
-				[]container.Option{
-					container.Border(linestyle.Light),
-					container.BorderTitle("Press Q to Quit"),
-				},
-				// row 1
-				grid.RowHeightPerc(80,
-					grid.Widget(wid.donTimer)),
-				// row 2
-				grid.RowHeightPercWithOpts(20,
-					[]container.Option{
-						container.AlignHorizontal(align.HorizontalCenter),
-					},
-					grid.Widget(wid.txtTimer,
-						container.AlignHorizontal(align.HorizontalCenter),
-						container.AlignVertical(align.VerticalMiddle),
-						container.PaddingLeftPercent(49),
+	switch layout {
+	case LayoutFull:
+		// first row
+		builder.Add(
+			grid.RowHeightPerc(30,
+				// col one
+				l.timer(30),
+				// col two
+				grid.ColWidthPerc(70,
+					grid.RowHeightPerc(80,
+						grid.Widget(l.wid.displayType, l.border()...),
+					),
+					grid.RowHeightPerc(20,
+						grid.Widget(l.wid.txtInfo, l.border()...),
 					),
 				),
 			),
-			// col two
-			grid.ColWidthPerc(70,
-				grid.RowHeightPerc(80,
-					grid.Widget(wid.displayType, container.Border(linestyle.Light)),
-				),
-				grid.RowHeightPerc(20,
-					grid.Widget(wid.txtInfo, container.Border(linestyle.Light)),
-				),
-			),
-		),
-	)
+		)
 
-	// Add second row
-	builder.Add(
-		grid.RowHeightPerc(10,
-			grid.ColWidthPerc(33,
-				grid.Widget(btnSet.btnStart),
-			),
-			grid.ColWidthPerc(33,
-				grid.Widget(btnSet.btnEnd),
-			),
-			grid.ColWidthPerc(33,
-				grid.Widget(btnSet.btnPause),
-			),
-		),
-	)
+		// Add second row
+		builder.Add(l.buttons(10))
 
-	// Add third row
-	builder.Add(
-		grid.RowHeightPerc(60,
-			grid.ColWidthPerc(30,
-				grid.Widget(sum.bcDay,
-					container.Border(linestyle.Light),
-					container.BorderTitle("Daily Summary (minutes)"),
+		// Add third row
+		builder.Add(
+			grid.RowHeightPerc(60,
+				grid.ColWidthPerc(30,
+					grid.Widget(l.sum.bcDay, append(l.border(),
+						container.BorderTitle("Daily Summary (minutes)"),
+					)...),
 				),
-			),
-			grid.ColWidthPerc(70,
-				grid.Widget(sum.lcWeekly,
-					container.Border(linestyle.Light),
-					container.BorderTitle("Weekly Summary"),
+				grid.ColWidthPerc(70,
+					grid.Widget(l.sum.lcWeekly, append(l.border(),
+						container.BorderTitle("Weekly Summary"),
+					)...),
 				),
 			),
-		),
-	)
+		)
+
+	case LayoutCompact:
+		builder.Add(grid.RowHeightPerc(75, l.timer(99)))
+		builder.Add(l.buttons(25))
 
-	gridOpts, err := builder.Build()
-	if err != nil {
-		return nil, err
+	case LayoutMinimal:
+		builder.Add(grid.Widget(l.wid.txtLine))
+
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLayout, layout)
 	}
 
-	c, err := container.New(term, gridOpts...)
-	if err != nil {
-		return nil, err
+	return builder.Build()
+}
+
+func (l *layouts) border() []container.Option {
+	return []container.Option{
+		container.Border(linestyle.Light),
+		container.BorderColor(l.theme.Border),
+		container.TitleColor(l.theme.Border),
 	}
+}
+
+// timer is the column with the donut and the time left.
+func (l *layouts) timer(widthPerc int) grid.Element {
+	return grid.ColWidthPercWithOpts(widthPerc,
+		append(l.border(),
+			container.BorderTitle("Press "+keyName(l.km[ActionQuit][0])+" to Quit"),
+		),
+		// row 1
+		grid.RowHeightPerc(80,
+			grid.Widget(l.wid.donTimer)),
+		// row 2
+		grid.RowHeightPercWithOpts(20,
+			[]container.Option{
+				container.AlignHorizontal(align.HorizontalCenter),
+			},
+			grid.Widget(l.wid.txtTimer,
+				container.AlignHorizontal(align.HorizontalCenter),
+				container.AlignVertical(align.VerticalMiddle),
+				container.PaddingLeftPercent(49),
+			),
+		),
+	)
+}
 
-	return c, nil
+// buttons is the row of buttons.
+func (l *layouts) buttons(heightPerc int) grid.Element {
+	return grid.RowHeightPerc(heightPerc,
+		grid.ColWidthPerc(33,
+			grid.Widget(l.btnSet.btnStart),
+		),
+		grid.ColWidthPerc(33,
+			grid.Widget(l.btnSet.btnEnd),
+		),
+		grid.ColWidthPerc(33,
+			grid.Widget(l.btnSet.btnPause),
+		),
+	)
 }
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mum4k/termdash/keyboard"
+)
+
+// Actions of the TUI that keys are bound to.
+const (
+	ActionStart  = "start"
+	ActionPause  = "pause"
+	ActionEnd    = "end"
+	ActionQuit   = "quit"
+	ActionLayout = "layout"
+)
+
+// Actions lists every action of the TUI.
+var Actions = []string{ActionStart, ActionPause, ActionEnd, ActionQuit, ActionLayout}
+
+var (
+	ErrUnknownAction = errors.New("Unknown TUI action")
+	ErrInvalidKey    = errors.New("Invalid key")
+	ErrKeyConflict   = errors.New("Key bound to several actions")
+)
+
+// Keymap binds the actions of the TUI to keys. A key is a character such
+// as "s" or "ñ", a name such as "space", "enter", "esc", "f5" or "up", or
+// "ctrl+" and a letter. Characters are case sensitive.
+type Keymap map[string][]string
+
+// DefaultKeymap returns the keys of the TUI when none are configured.
+func DefaultKeymap() Keymap {
+	return Keymap{
+		ActionStart:  {"s"},
+		ActionPause:  {"p"},
+		ActionEnd:    {"e"},
+		ActionQuit:   {"q", "Q"},
+		ActionLayout: {"l"},
+	}
+}
+
+// Merge returns the default keymap with the actions of km replacing their
+// default keys.
+func (km Keymap) Merge() Keymap {
+	merged := DefaultKeymap()
+	for action, keys := range km {
+		merged[action] = keys
+	}
+
+	return merged
+}
+
+// Validate reports unknown actions, actions without keys, keys that cannot
+// be parsed and keys bound to more than one action.
+func (km Keymap) Validate() error {
+	_, err := km.bindings()
+	return err
+}
+
+// bindings parses the keys of every action.
+func (km Keymap) bindings() (map[string][]keyboard.Key, error) {
+	for action := range km {
+		if !slices.Contains(Actions, action) {
+			return nil, fmt.Errorf("%w: %q, expected one of %s",
+				ErrUnknownAction, action, strings.Join(Actions, ", "))
+		}
+	}
+
+	bound := map[keyboard.Key]string{}
+	b := map[string][]keyboard.Key{}
+
+	for _, action := range Actions {
+		if len(km[action]) == 0 {
+			return nil, fmt.Errorf("%w: no key for %s", ErrInvalidKey, action)
+		}
+
+		for _, name := range km[action] {
+			k, err := parseKey(name)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", action, err)
+			}
+
+			if other, ok := bound[k]; ok && other != action {
+				return nil, fmt.Errorf("%w: %q for both %s and %s", ErrKeyConflict, name, other, action)
+			}
+			bound[k] = action
+
+			b[action] = append(b[action], k)
+		}
+	}
+
+	return b, nil
+}
+
+var namedKeys = map[string]keyboard.Key{
+	"space":     keyboard.KeySpace,
+	"enter":     keyboard.KeyEnter,
+	"esc":       keyboard.KeyEsc,
+	"tab":       keyboard.KeyTab,
+	"backspace": keyboard.KeyBackspace2,
+	"insert":    keyboard.KeyInsert,
+	"delete":    keyboard.KeyDelete,
+	"home":      keyboard.KeyHome,
+	"end":       keyboard.KeyEnd,
+	"pgup":      keyboard.KeyPgUp,
+	"pgdn":      keyboard.KeyPgDn,
+	"up":        keyboard.KeyArrowUp,
+	"down":      keyboard.KeyArrowDown,
+	"left":      keyboard.KeyArrowLeft,
+	"right":     keyboard.KeyArrowRight,
+}
+
+var functionKeys = []keyboard.Key{
+	keyboard.KeyF1, keyboard.KeyF2, keyboard.KeyF3, keyboard.KeyF4,
+	keyboard.KeyF5, keyboard.KeyF6, keyboard.KeyF7, keyboard.KeyF8,
+	keyboard.KeyF9, keyboard.KeyF10, keyboard.KeyF11, keyboard.KeyF12,
+}
+
+// ctrlKeys are ctrl+a to ctrl+z. Terminals send ctrl+h, ctrl+i and ctrl+m
+// as backspace, tab and enter.
+var ctrlKeys = []keyboard.Key{
+	keyboard.KeyCtrlA, keyboard.KeyCtrlB, keyboard.KeyCtrlC, keyboard.KeyCtrlD,
+	keyboard.KeyCtrlE, keyboard.KeyCtrlF, keyboard.KeyCtrlG, keyboard.KeyCtrlH,
+	keyboard.KeyCtrlI, keyboard.KeyCtrlJ, keyboard.KeyCtrlK, keyboard.KeyCtrlL,
+	keyboard.KeyCtrlM, keyboard.KeyCtrlN, keyboard.KeyCtrlO, keyboard.KeyCtrlP,
+	keyboard.KeyCtrlQ, keyboard.KeyCtrlR, keyboard.KeyCtrlS, keyboard.KeyCtrlT,
+	keyboard.KeyCtrlU, keyboard.KeyCtrlV, keyboard.KeyCtrlW, keyboard.KeyCtrlX,
+	keyboard.KeyCtrlY, keyboard.KeyCtrlZ,
+}
+
+func parseKey(name string) (keyboard.Key, error) {
+	if r, size := utf8.DecodeRuneInString(name); size == len(name) && r != utf8.RuneError && unicode.IsPrint(r) {
+		return keyboard.Key(r), nil
+	}
+
+	lower := strings.ToLower(name)
+
+	if k, ok := namedKeys[lower]; ok {
+		return k, nil
+	}
+
+	var n int
+	if _, err := fmt.Sscanf(lower, "f%d", &n); err == nil && fmt.Sprintf("f%d", n) == lower &&
+		n >= 1 && n <= len(functionKeys) {
+		return functionKeys[n-1], nil
+	}
+
+	if letter, ok := strings.CutPrefix(lower, "ctrl+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return ctrlKeys[letter[0]-'a'], nil
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidKey, name)
+}
+
+// label marks the first key of action in word, as in "(S)tart", or adds it
+// after word when word does not contain it.
+func (km Keymap) label(action, word string) string {
+	key := km[action][0]
+
+	if r, size := utf8.DecodeRuneInString(key); size == len(key) && unicode.IsLetter(r) {
+		if k := strings.IndexFunc(word, func(c rune) bool {
+			return unicode.ToLower(c) == unicode.ToLower(r)
+		}); k >= 0 {
+			_, n := utf8.DecodeRuneInString(word[k:])
+			return word[:k] + "(" + word[k:k+n] + ")" + word[k+n:]
+		}
+	}
+
+	return word + " (" + keyName(key) + ")"
+}
+
+// keyName shows key the way it is typed, with letters upper case like on
+// the keyboard.
+func keyName(key string) string {
+	if r, size := utf8.DecodeRuneInString(key); size == len(key) && unicode.IsLetter(r) {
+		return string(unicode.ToUpper(r))
+	}
+
+	return key
+}
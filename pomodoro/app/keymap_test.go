@@ -0,0 +1,55 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestKeymapValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		keymap Keymap
+		expErr error
+	}{
+		{name: "Default", keymap: DefaultKeymap()},
+		{name: "Named", keymap: Keymap{"start": {"space"}, "end": {"ctrl+e", "F5"}, "quit": {"esc"}}.Merge()},
+		{name: "UnknownAction", keymap: Keymap{"skip": {"k"}}.Merge(), expErr: ErrUnknownAction},
+		{name: "InvalidKey", keymap: Keymap{"start": {"f13"}}.Merge(), expErr: ErrInvalidKey},
+		{name: "NoKey", keymap: Keymap{"pause": {}}.Merge(), expErr: ErrInvalidKey},
+		{name: "Conflict", keymap: Keymap{"end": {"s"}}.Merge(), expErr: ErrKeyConflict},
+		{name: "Alias", keymap: Keymap{"start": {"ctrl+m"}, "end": {"enter"}}.Merge(), expErr: ErrKeyConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.keymap.Validate()
+			if tc.expErr == nil && err != nil {
+				t.Fatalf("Expected no error, got %q.\n", err)
+			}
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("Expected error %q, got %v instead.\n", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestKeymapLabel(t *testing.T) {
+	testCases := []struct {
+		name     string
+		keymap   Keymap
+		expLabel string
+	}{
+		{name: "Default", keymap: DefaultKeymap(), expLabel: "(P)ause"},
+		{name: "Inside", keymap: Keymap{"pause": {"u"}}, expLabel: "Pa(u)se"},
+		{name: "Missing", keymap: Keymap{"pause": {"x"}}, expLabel: "Pause (X)"},
+		{name: "Named", keymap: Keymap{"pause": {"space"}}, expLabel: "Pause (space)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if label := tc.keymap.label(ActionPause, "Pause"); label != tc.expLabel {
+				t.Errorf("Expected %q, got %q instead.\n", tc.expLabel, label)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"slices"
+
+	"github.com/mum4k/termdash/terminal/terminalapi"
+)
+
+// Layouts of the TUI. The auto layout picks the most detailed layout that
+// fits the terminal.
+const (
+	LayoutAuto    = "auto"
+	LayoutFull    = "full"
+	LayoutCompact = "compact"
+	LayoutMinimal = "minimal"
+)
+
+// Layouts lists the layouts from the most to the least detailed. The layout
+// key goes through them, and then back to auto.
+var Layouts = []string{LayoutFull, LayoutCompact, LayoutMinimal}
+
+var ErrUnknownLayout = errors.New("Unknown layout")
+
+// Smallest terminals, in cells, that the full and compact layouts fit in.
+var (
+	fullSize    = image.Point{X: 80, Y: 30}
+	compactSize = image.Point{X: 32, Y: 12}
+)
+
+// ValidateLayout reports a layout that is neither one of Layouts nor auto.
+func ValidateLayout(layout string) error {
+	if layout != LayoutAuto && !slices.Contains(Layouts, layout) {
+		return fmt.Errorf("%w: %q, expected %s or one of %v", ErrUnknownLayout, layout, LayoutAuto, Layouts)
+	}
+
+	return nil
+}
+
+// autoLayout returns the most detailed layout fitting a terminal of the
+// given size.
+func autoLayout(size image.Point) string {
+	switch {
+	case size.X >= fullSize.X && size.Y >= fullSize.Y:
+		return LayoutFull
+	case size.X >= compactSize.X && size.Y >= compactSize.Y:
+		return LayoutCompact
+	default:
+		return LayoutMinimal
+	}
+}
+
+// nextLayout returns the layout after current in Layouts, auto after the
+// last and the first after auto.
+func nextLayout(current string) string {
+	cycle := append(slices.Clone(Layouts), LayoutAuto)
+
+	k := slices.Index(cycle, current)
+	return cycle[(k+1)%len(cycle)]
+}
+
+// resizeNotifier passes on the events of a terminal, signaling each resize
+// on resized without waiting for it to be received.
+type resizeNotifier struct {
+	terminalapi.Terminal
+	resized chan<- struct{}
+}
+
+func (t *resizeNotifier) Event(ctx context.Context) terminalapi.Event {
+	ev := t.Terminal.Event(ctx)
+
+	if _, ok := ev.(*terminalapi.Resize); ok {
+		select {
+		case t.resized <- struct{}{}:
+		default:
+		}
+	}
+
+	return ev
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/mum4k/termdash/container"
+	"github.com/mum4k/termdash/keyboard"
+	"github.com/mum4k/termdash/terminal/terminalapi"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+func TestAutoLayout(t *testing.T) {
+	testCases := []struct {
+		name      string
+		size      image.Point
+		expLayout string
+	}{
+		{name: "Large", size: image.Point{X: 200, Y: 60}, expLayout: LayoutFull},
+		{name: "Full", size: fullSize, expLayout: LayoutFull},
+		{name: "Short", size: image.Point{X: 200, Y: 29}, expLayout: LayoutCompact},
+		{name: "Pane", size: image.Point{X: 40, Y: 15}, expLayout: LayoutCompact},
+		{name: "Narrow", size: image.Point{X: 31, Y: 40}, expLayout: LayoutMinimal},
+		{name: "Line", size: image.Point{X: 120, Y: 1}, expLayout: LayoutMinimal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if layout := autoLayout(tc.size); layout != tc.expLayout {
+				t.Errorf("Expected %q, got %q instead.\n", tc.expLayout, layout)
+			}
+		})
+	}
+}
+
+func TestNextLayout(t *testing.T) {
+	layout := LayoutCompact
+
+	for _, exp := range []string{LayoutMinimal, LayoutAuto, LayoutFull, LayoutCompact} {
+		layout = nextLayout(layout)
+		if layout != exp {
+			t.Errorf("Expected %q, got %q instead.\n", exp, layout)
+		}
+	}
+
+	if err := ValidateLayout("tiny"); !errors.Is(err, ErrUnknownLayout) {
+		t.Errorf("Expected error %q, got %v instead.\n", ErrUnknownLayout, err)
+	}
+}
+
+func TestLayoutOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := pomodoro.NewConfig(repository.NewInMemoryRepo(), 0, 0, 0)
+	theme := Themes[DefaultTheme]
+	errCh := make(chan error)
+
+	wid, err := newWidgets(ctx, theme, errCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := newSummary(ctx, config, theme, errCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	km := DefaultKeymap()
+	keys, err := km.bindings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	btnSet, err := newButtonSet(nil, wid, sum, km, theme, keys, nil, errCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := &layouts{btnSet: btnSet, wid: wid, sum: sum, km: km, theme: theme}
+
+	root, err := container.New(&eventTerminal{}, container.ID(rootID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Goes back to the first layout to switch between all of them.
+	for _, layout := range append(Layouts, Layouts[0]) {
+		t.Run(layout, func(t *testing.T) {
+			opts, err := l.options(layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := root.Update(rootID, opts...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	if _, err := l.options(LayoutAuto); !errors.Is(err, ErrUnknownLayout) {
+		t.Errorf("Expected error %q, got %v instead.\n", ErrUnknownLayout, err)
+	}
+}
+
+// eventTerminal is a terminal returning the queued events.
+type eventTerminal struct {
+	terminalapi.Terminal
+	events []terminalapi.Event
+}
+
+func (t *eventTerminal) Event(context.Context) terminalapi.Event {
+	ev := t.events[0]
+	t.events = t.events[1:]
+
+	return ev
+}
+
+func TestResizeNotifier(t *testing.T) {
+	resized := make(chan struct{}, 1)
+	term := &resizeNotifier{
+		Terminal: &eventTerminal{events: []terminalapi.Event{
+			&terminalapi.Keyboard{Key: keyboard.Key('s')},
+			&terminalapi.Resize{Size: image.Point{X: 40, Y: 15}},
+			&terminalapi.Resize{Size: image.Point{X: 30, Y: 10}},
+		}},
+		resized: resized,
+	}
+
+	if _, ok := term.Event(context.Background()).(*terminalapi.Keyboard); !ok {
+		t.Fatal("Expected the keyboard event to pass through")
+	}
+	if len(resized) != 0 {
+		t.Fatal("Expected no resize signal for a keyboard event")
+	}
+
+	// The second resize must not block while the first is pending.
+	term.Event(context.Background())
+	term.Event(context.Background())
+
+	if len(resized) != 1 {
+		t.Errorf("Expected 1 pending resize signal, got %d instead.\n", len(resized))
+	}
+}
@@ -22,6 +22,7 @@ type summary struct {
 func newSummary(
 	ctx context.Context,
 	config *pomodoro.IntervalConfig,
+	theme Theme,
 	errorCh chan<- error,
 ) (*summary, error) {
 	s := &summary{
@@ -31,12 +32,12 @@ func newSummary(
 
 	var err error
 
-	s.bcDay, err = newBarChart(ctx, config, s.updateDaily, errorCh)
+	s.bcDay, err = newBarChart(ctx, config, theme, s.updateDaily, errorCh)
 	if err != nil {
 		return nil, err
 	}
 
-	s.lcWeekly, err = newLineChart(ctx, config, s.updateWeekly, errorCh)
+	s.lcWeekly, err = newLineChart(ctx, config, theme, s.updateWeekly, errorCh)
 	if err != nil {
 		return nil, err
 	}
@@ -47,22 +48,31 @@ func newSummary(
 func newBarChart(
 	ctx context.Context,
 	config *pomodoro.IntervalConfig,
+	theme Theme,
 	updateCh <-chan bool,
 	errCh chan<- error,
 ) (*barchart.BarChart, error) {
 	bc, err := barchart.New(
 		barchart.ShowValues(),
 		barchart.BarColors([]cell.Color{
-			cell.ColorPurple,
-			cell.ColorGreen,
+			theme.category(pomodoro.CategoryPomodoro),
+			theme.category(CategoryBreak),
+			theme.category(CategoryIdle),
 		}),
 		barchart.ValueColors([]cell.Color{
-			cell.ColorBlack,
-			cell.ColorBlack,
+			theme.Values,
+			theme.Values,
+			theme.Values,
+		}),
+		barchart.LabelColors([]cell.Color{
+			theme.Text,
+			theme.Text,
+			theme.Text,
 		}),
 		barchart.Labels([]string{
-			"Pomodoro",
-			"Break",
+			pomodoro.CategoryPomodoro,
+			CategoryBreak,
+			CategoryIdle,
 		}),
 	)
 	if err != nil {
@@ -78,10 +88,11 @@ func newBarChart(
 		return bc.Values([]int{
 			int(ds[0].Minutes()),
 			int(ds[1].Minutes()),
+			int(ds[2].Minutes()),
 		},
 			int(math.Max(
-				ds[0].Minutes(),
-				ds[1].Minutes())*1.1)+1,
+				math.Max(ds[0].Minutes(), ds[1].Minutes()),
+				ds[2].Minutes())*1.1)+1,
 		)
 	}
 
@@ -106,13 +117,14 @@ func newBarChart(
 func newLineChart(
 	ctx context.Context,
 	config *pomodoro.IntervalConfig,
+	theme Theme,
 	updateCh <-chan bool,
 	errCh chan<- error,
 ) (*linechart.LineChart, error) {
 	lc, err := linechart.New(
-		linechart.AxesCellOpts(cell.FgColor(cell.ColorRed)),
-		linechart.YLabelCellOpts(cell.FgColor(cell.ColorPurple)),
-		linechart.XLabelCellOpts(cell.FgColor(cell.ColorCyan)),
+		linechart.AxesCellOpts(cell.FgColor(theme.Axes)),
+		linechart.YLabelCellOpts(cell.FgColor(theme.YLabels)),
+		linechart.XLabelCellOpts(cell.FgColor(theme.XLabels)),
 		linechart.YAxisFormattedValues(
 			linechart.ValueFormatterSingleUnitDuration(time.Second, 0),
 		),
@@ -128,17 +140,25 @@ func newLineChart(
 		}
 
 		err = lc.Series(ws[0].Name, ws[0].Values,
-			linechart.SeriesCellOpts(cell.FgColor(cell.ColorBlue)),
+			linechart.SeriesCellOpts(cell.FgColor(theme.category(ws[0].Name))),
 			linechart.SeriesXLabels(ws[1].Labels),
 		)
 		if err != nil {
 			return err
 		}
 
-		return lc.Series(ws[1].Name, ws[1].Values,
-			linechart.SeriesCellOpts(cell.FgColor(cell.ColorYellow)),
+		err = lc.Series(ws[1].Name, ws[1].Values,
+			linechart.SeriesCellOpts(cell.FgColor(theme.category(ws[1].Name))),
 			linechart.SeriesXLabels(ws[1].Labels),
 		)
+		if err != nil {
+			return err
+		}
+
+		return lc.Series(ws[2].Name, ws[2].Values,
+			linechart.SeriesCellOpts(cell.FgColor(theme.category(ws[2].Name))),
+			linechart.SeriesXLabels(ws[2].Labels),
+		)
 	}
 
 	go func() {
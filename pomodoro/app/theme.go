@@ -0,0 +1,253 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mum4k/termdash/cell"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// Categories of the summaries that are not interval categories. They match
+// the series names of pomodoro.DailySummary and pomodoro.RangeSummary.
+const (
+	CategoryBreak = "Break"
+	CategoryIdle  = "Idle"
+)
+
+var (
+	ErrUnknownTheme = errors.New("Unknown theme")
+	ErrInvalidColor = errors.New("Invalid color")
+)
+
+// Theme holds the colors of the TUI.
+type Theme struct {
+	// Text colors the info and timer texts.
+	Text cell.Color
+	// Border colors the borders of the panels and their titles.
+	Border cell.Color
+	// Axes, XLabels and YLabels color the weekly chart.
+	Axes    cell.Color
+	XLabels cell.Color
+	YLabels cell.Color
+	// Values colors the minutes written on the daily bars.
+	Values cell.Color
+	// Start, Pause and End fill the buttons, and ButtonText writes on them.
+	Start      cell.Color
+	Pause      cell.Color
+	End        cell.Color
+	ButtonText cell.Color
+	// Categories colors the donut and the category display by the running
+	// interval, and the charts by series: the interval categories, along
+	// with CategoryBreak and CategoryIdle.
+	Categories map[string]cell.Color
+}
+
+// Themes are the built-in themes by name.
+var Themes = map[string]Theme{
+	"dark": {
+		Axes:       cell.ColorRed,
+		XLabels:    cell.ColorCyan,
+		YLabels:    cell.ColorPurple,
+		Values:     cell.ColorBlack,
+		Start:      cell.ColorNumber(117),
+		Pause:      cell.ColorNumber(220),
+		End:        cell.ColorRed,
+		ButtonText: cell.ColorBlack,
+		Categories: map[string]cell.Color{
+			pomodoro.CategoryPomodoro:   cell.ColorPurple,
+			pomodoro.CategoryShortBreak: cell.ColorGreen,
+			pomodoro.CategoryLongBreak:  cell.ColorGreen,
+			CategoryBreak:               cell.ColorGreen,
+			CategoryIdle:                cell.ColorRed,
+		},
+	},
+	"light": {
+		Text:       cell.ColorBlack,
+		Border:     cell.ColorNumber(240),
+		Axes:       cell.ColorNumber(240),
+		XLabels:    cell.ColorNumber(236),
+		YLabels:    cell.ColorNumber(236),
+		Values:     cell.ColorWhite,
+		Start:      cell.ColorNumber(25),
+		Pause:      cell.ColorNumber(136),
+		End:        cell.ColorNumber(160),
+		ButtonText: cell.ColorWhite,
+		Categories: map[string]cell.Color{
+			pomodoro.CategoryPomodoro:   cell.ColorNumber(91),
+			pomodoro.CategoryShortBreak: cell.ColorNumber(28),
+			pomodoro.CategoryLongBreak:  cell.ColorNumber(22),
+			CategoryBreak:               cell.ColorNumber(28),
+			CategoryIdle:                cell.ColorNumber(160),
+		},
+	},
+	"high-contrast": {
+		Text:       cell.ColorWhite,
+		Border:     cell.ColorWhite,
+		Axes:       cell.ColorWhite,
+		XLabels:    cell.ColorWhite,
+		YLabels:    cell.ColorWhite,
+		Values:     cell.ColorBlack,
+		Start:      cell.ColorAqua,
+		Pause:      cell.ColorYellow,
+		End:        cell.ColorRed,
+		ButtonText: cell.ColorBlack,
+		Categories: map[string]cell.Color{
+			pomodoro.CategoryPomodoro:   cell.ColorYellow,
+			pomodoro.CategoryShortBreak: cell.ColorAqua,
+			pomodoro.CategoryLongBreak:  cell.ColorLime,
+			CategoryBreak:               cell.ColorAqua,
+			CategoryIdle:                cell.ColorFuchsia,
+		},
+	},
+}
+
+// DefaultTheme is the name of the theme used when none is configured.
+const DefaultTheme = "dark"
+
+// themeKeys are the configuration keys of the colors of a Theme.
+var themeKeys = map[string]func(t *Theme) *cell.Color{
+	"text":        func(t *Theme) *cell.Color { return &t.Text },
+	"border":      func(t *Theme) *cell.Color { return &t.Border },
+	"axes":        func(t *Theme) *cell.Color { return &t.Axes },
+	"x_labels":    func(t *Theme) *cell.Color { return &t.XLabels },
+	"y_labels":    func(t *Theme) *cell.Color { return &t.YLabels },
+	"values":      func(t *Theme) *cell.Color { return &t.Values },
+	"start":       func(t *Theme) *cell.Color { return &t.Start },
+	"pause":       func(t *Theme) *cell.Color { return &t.Pause },
+	"end":         func(t *Theme) *cell.Color { return &t.End },
+	"button_text": func(t *Theme) *cell.Color { return &t.ButtonText },
+}
+
+// categoryKeys are the configuration keys of the category colors.
+var categoryKeys = map[string]string{
+	"pomodoro":    pomodoro.CategoryPomodoro,
+	"short_break": pomodoro.CategoryShortBreak,
+	"long_break":  pomodoro.CategoryLongBreak,
+	"break":       CategoryBreak,
+	"idle":        CategoryIdle,
+}
+
+// LoadTheme returns the theme called name, either one of custom or one of
+// Themes. A custom theme sets colors by key over the built-in theme named
+// by its "base" key, or DefaultTheme. Names are case insensitive.
+func LoadTheme(name string, custom map[string]map[string]string) (Theme, error) {
+	name = strings.ToLower(name)
+	if name == "" {
+		name = DefaultTheme
+	}
+
+	colors, ok := custom[name]
+	if !ok {
+		t, ok := Themes[name]
+		if !ok {
+			return Theme{}, fmt.Errorf("%w: %q", ErrUnknownTheme, name)
+		}
+
+		return t.With(nil)
+	}
+
+	base := strings.ToLower(colors["base"])
+	if base == "" {
+		base = DefaultTheme
+	}
+
+	t, ok := Themes[base]
+	if !ok {
+		return Theme{}, fmt.Errorf("%w: %q, base of %q", ErrUnknownTheme, base, name)
+	}
+
+	colors = maps.Clone(colors)
+	delete(colors, "base")
+
+	return t.With(colors)
+}
+
+// With returns a copy of t with the colors of the given keys replaced.
+func (t Theme) With(colors map[string]string) (Theme, error) {
+	t.Categories = maps.Clone(t.Categories)
+	if t.Categories == nil {
+		t.Categories = map[string]cell.Color{}
+	}
+
+	for key, value := range colors {
+		c, err := ParseColor(value)
+		if err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", key, err)
+		}
+
+		if field, ok := themeKeys[key]; ok {
+			*field(&t) = c
+			continue
+		}
+
+		category, ok := categoryKeys[key]
+		if !ok {
+			keys := append(slices.Sorted(maps.Keys(themeKeys)), slices.Sorted(maps.Keys(categoryKeys))...)
+			return Theme{}, fmt.Errorf("%w: unknown key %q, expected one of %s",
+				ErrInvalidColor, key, strings.Join(keys, ", "))
+		}
+		t.Categories[category] = c
+	}
+
+	return t, nil
+}
+
+// category returns the color of a category, falling back to the color of
+// the text.
+func (t Theme) category(name string) cell.Color {
+	if c, ok := t.Categories[name]; ok {
+		return c
+	}
+
+	return t.Text
+}
+
+var colorNames = map[string]cell.Color{
+	"default": cell.ColorDefault,
+	"black":   cell.ColorBlack,
+	"maroon":  cell.ColorMaroon,
+	"green":   cell.ColorGreen,
+	"olive":   cell.ColorOlive,
+	"navy":    cell.ColorNavy,
+	"purple":  cell.ColorPurple,
+	"magenta": cell.ColorMagenta,
+	"teal":    cell.ColorTeal,
+	"cyan":    cell.ColorCyan,
+	"silver":  cell.ColorSilver,
+	"gray":    cell.ColorGray,
+	"red":     cell.ColorRed,
+	"lime":    cell.ColorLime,
+	"yellow":  cell.ColorYellow,
+	"blue":    cell.ColorBlue,
+	"fuchsia": cell.ColorFuchsia,
+	"aqua":    cell.ColorAqua,
+	"white":   cell.ColorWhite,
+}
+
+// ParseColor parses a color name such as "purple", a number of the 256
+// color palette or a web color such as "#5f00af".
+func ParseColor(s string) (cell.Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if c, ok := colorNames[s]; ok {
+		return c, nil
+	}
+
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return cell.ColorNumber(n), nil
+	}
+
+	if hex, ok := strings.CutPrefix(s, "#"); ok && len(hex) == 6 {
+		if rgb, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return cell.ColorRGB24(int(rgb>>16), int(rgb>>8&0xff), int(rgb&0xff)), nil
+		}
+	}
+
+	return cell.ColorDefault, fmt.Errorf("%w: %q", ErrInvalidColor, s)
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mum4k/termdash/cell"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func TestParseColor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		color    string
+		expColor cell.Color
+		expErr   error
+	}{
+		{name: "Name", color: "Purple", expColor: cell.ColorPurple},
+		{name: "Number", color: "220", expColor: cell.ColorNumber(220)},
+		{name: "Web", color: "#5f00af", expColor: cell.ColorRGB24(0x5f, 0x00, 0xaf)},
+		{name: "OutOfRange", color: "256", expErr: ErrInvalidColor},
+		{name: "Unknown", color: "chartreuse", expErr: ErrInvalidColor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := ParseColor(tc.color)
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("Expected error %q, got %v instead.\n", tc.expErr, err)
+			}
+
+			if c != tc.expColor {
+				t.Errorf("Expected %v, got %v instead.\n", tc.expColor, c)
+			}
+		})
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	custom := map[string]map[string]string{
+		"mine":   {"base": "light", "pause": "136", "pomodoro": "blue"},
+		"broken": {"pomodoro": "chartreuse"},
+		"typo":   {"pomodor": "blue"},
+		"orphan": {"base": "sepia"},
+	}
+
+	testCases := []struct {
+		name     string
+		theme    string
+		expPause cell.Color
+		expPomo  cell.Color
+		expErr   error
+	}{
+		{name: "Default", expPause: cell.ColorNumber(220), expPomo: cell.ColorPurple},
+		{name: "Builtin", theme: "High-Contrast", expPause: cell.ColorYellow, expPomo: cell.ColorYellow},
+		{name: "Custom", theme: "mine", expPause: cell.ColorNumber(136), expPomo: cell.ColorBlue},
+		{name: "Unknown", theme: "sepia", expErr: ErrUnknownTheme},
+		{name: "UnknownBase", theme: "orphan", expErr: ErrUnknownTheme},
+		{name: "InvalidColor", theme: "broken", expErr: ErrInvalidColor},
+		{name: "InvalidKey", theme: "typo", expErr: ErrInvalidColor},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			theme, err := LoadTheme(tc.theme, custom)
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("Expected error %q, got %v instead.\n", tc.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if theme.Pause != tc.expPause {
+				t.Errorf("Expected pause %v, got %v instead.\n", tc.expPause, theme.Pause)
+			}
+			if c := theme.category(pomodoro.CategoryPomodoro); c != tc.expPomo {
+				t.Errorf("Expected pomodoro %v, got %v instead.\n", tc.expPomo, c)
+			}
+		})
+	}
+
+	if c := Themes["light"].Categories[pomodoro.CategoryPomodoro]; c != cell.ColorNumber(91) {
+		t.Errorf("Expected custom themes to leave the built-in ones alone, got %v\n", c)
+	}
+}
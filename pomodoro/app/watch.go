@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// watchChanges polls the repository for writes made by other processes and
+// refreshes the widgets, so the TUI follows an interval that is paused,
+// ended or driven from elsewhere within a second.
+func watchChanges(
+	ctx context.Context,
+	config *pomodoro.IntervalConfig,
+	wid *widgets,
+	sum *summary,
+	redrawCh chan<- bool,
+	errCh chan<- error,
+) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := pomodoro.Changed(config)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			if !changed {
+				continue
+			}
+
+			i, err := pomodoro.Current(config)
+			if err == pomodoro.ErrNoInterval {
+				continue
+			}
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			switch i.State {
+			case pomodoro.StateRunning:
+				msg := "Take a break"
+				if i.Category == pomodoro.CategoryPomodoro {
+					msg = "Focus on your task"
+				}
+
+				wid.update(
+					[]int{int(i.ActualDuration), int(i.PlannedDuration)},
+					i.Category, msg,
+					fmt.Sprint(i.PlannedDuration-i.ActualDuration), redrawCh)
+
+			case pomodoro.StatePaused:
+				wid.update([]int{}, i.Category,
+					"Paused... press start to continue", "", redrawCh)
+
+			default:
+				wid.update([]int{}, "", "Nothing running...", "", redrawCh)
+			}
+
+			sum.update(redrawCh)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"github.com/mum4k/termdash/cell"
 	"github.com/mum4k/termdash/widgets/donut"
@@ -15,39 +16,60 @@ type widgets struct {
 	displayType *segmentdisplay.SegmentDisplay
 	txtInfo     *text.Text
 	txtTimer    *text.Text
+	txtLine     *text.Text
 
 	updateDonTimer chan []int
 	updateTxtInfo  chan string
 	updateTxtTimer chan string
 	updateTxtType  chan string
+	updateTxtLine  chan status
+
+	theme Theme
+
+	// mu guards status, the latest texts shown, which the minimal layout
+	// writes on a single line.
+	mu     sync.Mutex
+	status status
+}
+
+// status holds the texts of the widgets.
+type status struct {
+	category, info, timer string
 }
 
-func newWidgets(ctx context.Context, errorCh chan<- error) (*widgets, error) {
+func newWidgets(ctx context.Context, theme Theme, errorCh chan<- error) (*widgets, error) {
 	w := &widgets{
+		theme:          theme,
 		updateDonTimer: make(chan []int),
 		updateTxtInfo:  make(chan string),
 		updateTxtTimer: make(chan string),
 		updateTxtType:  make(chan string),
+		updateTxtLine:  make(chan status),
 	}
 
 	var err error
 
-	w.donTimer, err = newDonut(ctx, w.updateDonTimer, errorCh)
+	w.donTimer, err = newDonut(ctx, w.updateDonTimer, w.donutColor, errorCh)
 	if err != nil {
 		return nil, err
 	}
 
-	w.displayType, err = newSegmentDisplay(ctx, w.updateTxtType, errorCh)
+	w.displayType, err = newSegmentDisplay(ctx, w.updateTxtType, theme, errorCh)
 	if err != nil {
 		return nil, err
 	}
 
-	w.txtInfo, err = newText(ctx, w.updateTxtInfo, errorCh)
+	w.txtInfo, err = newText(ctx, w.updateTxtInfo, theme.Text, errorCh)
 	if err != nil {
 		return nil, err
 	}
 
-	w.txtTimer, err = newText(ctx, w.updateTxtTimer, errorCh)
+	w.txtTimer, err = newText(ctx, w.updateTxtTimer, theme.Text, errorCh)
+	if err != nil {
+		return nil, err
+	}
+
+	w.txtLine, err = newLine(ctx, w.updateTxtLine, theme, errorCh)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +82,8 @@ func (w *widgets) update(
 	txtType, txtInfo, txtTimer string,
 	redrawCh chan<- bool,
 ) {
+	st := w.setStatus(txtType, txtInfo, txtTimer)
+
 	if txtInfo != "" {
 		w.updateTxtInfo <- txtInfo
 	}
@@ -76,12 +100,41 @@ func (w *widgets) update(
 		w.updateDonTimer <- timer
 	}
 
+	w.updateTxtLine <- st
+
 	redrawCh <- true
 }
 
+// setStatus records the texts that are not empty and returns the status.
+func (w *widgets) setStatus(category, info, timer string) status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if category != "" {
+		w.status.category = category
+	}
+	if info != "" {
+		w.status.info = info
+	}
+	if timer != "" {
+		w.status.timer = timer
+	}
+
+	return w.status
+}
+
+// donutColor returns the color of the category of the latest interval.
+func (w *widgets) donutColor() cell.Color {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.theme.category(w.status.category)
+}
+
 func newText(
 	ctx context.Context,
 	updateText <-chan string,
+	color cell.Color,
 	errorCh chan<- error,
 ) (*text.Text, error) {
 	txt, err := text.New()
@@ -94,7 +147,54 @@ func newText(
 			select {
 			case t := <-updateText:
 				txt.Reset()
-				errorCh <- txt.Write(t)
+				errorCh <- txt.Write(t, text.WriteCellOpts(cell.FgColor(color)))
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return txt, nil
+}
+
+// newLine returns a text writing the category, the time left and the info
+// on one line.
+func newLine(
+	ctx context.Context,
+	updateLine <-chan status,
+	theme Theme,
+	errorCh chan<- error,
+) (*text.Text, error) {
+	txt, err := text.New()
+	if err != nil {
+		return nil, err
+	}
+
+	write := func(st status) error {
+		txt.Reset()
+
+		if st.category != "" {
+			err := txt.Write(strings.ToUpper(st.category)+" ",
+				text.WriteCellOpts(cell.FgColor(theme.category(st.category)), cell.Bold()))
+			if err != nil {
+				return err
+			}
+		}
+
+		rest := strings.TrimSpace(st.timer + "  " + st.info)
+		if rest == "" {
+			return nil
+		}
+
+		return txt.Write(rest, text.WriteCellOpts(cell.FgColor(theme.Text)))
+	}
+
+	go func() {
+		for {
+			select {
+			case st := <-updateLine:
+				errorCh <- write(st)
 
 			case <-ctx.Done():
 				return
@@ -108,11 +208,12 @@ func newText(
 func newDonut(
 	ctx context.Context,
 	donUpdater <-chan []int,
+	color func() cell.Color,
 	errCh chan<- error,
 ) (*donut.Donut, error) {
 	don, err := donut.New(
 		donut.Clockwise(),
-		donut.CellOpts(cell.FgColor(cell.ColorPurple)),
+		donut.CellOpts(cell.FgColor(color())),
 	)
 	if err != nil {
 		return nil, err
@@ -123,7 +224,8 @@ func newDonut(
 			select {
 			case d := <-donUpdater:
 				if d[0] <= d[1] {
-					errCh <- don.Absolute(d[1]-d[0], d[1])
+					errCh <- don.Absolute(d[1]-d[0], d[1],
+						donut.CellOpts(cell.FgColor(color())))
 				}
 
 			case <-ctx.Done():
@@ -138,6 +240,7 @@ func newDonut(
 func newSegmentDisplay(
 	ctx context.Context,
 	updateText <-chan string,
+	theme Theme,
 	errCh chan<- error,
 ) (*segmentdisplay.SegmentDisplay, error) {
 	sd, err := segmentdisplay.New()
@@ -154,7 +257,8 @@ func newSegmentDisplay(
 				}
 
 				errCh <- sd.Write([]*segmentdisplay.TextChunk{
-					segmentdisplay.NewChunk(strings.ToUpper(t)),
+					segmentdisplay.NewChunk(strings.ToUpper(t),
+						segmentdisplay.WriteCellOpts(cell.FgColor(theme.category(t)))),
 				})
 
 			case <-ctx.Done():
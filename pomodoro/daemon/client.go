@@ -0,0 +1,126 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// Client is a pomodoro.Controller backed by a daemon. Every call uses its
+// own connection.
+type Client struct {
+	path string
+}
+
+// Dial returns a client for the daemon listening on path, or an error if
+// none answers.
+func Dial(path string) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+
+	return &Client{path: path}, nil
+}
+
+func (c *Client) Status() (pomodoro.Interval, error) { return c.call("status") }
+func (c *Client) Start() (pomodoro.Interval, error)  { return c.call("start") }
+func (c *Client) Resume() (pomodoro.Interval, error) { return c.call("resume") }
+func (c *Client) Pause() (pomodoro.Interval, error)  { return c.call("pause") }
+func (c *Client) End() (pomodoro.Interval, error)    { return c.call("end") }
+func (c *Client) Skip() (pomodoro.Interval, error)   { return c.call("skip") }
+
+func (c *Client) Extend(d time.Duration) (pomodoro.Interval, error) {
+	return c.do(request{Action: "extend", Seconds: d.Seconds()})
+}
+
+func (c *Client) Subscribe() (<-chan pomodoro.Event, func(), error) {
+	conn, dec, err := c.send(request{Action: "subscribe"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := readResponse(dec); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan pomodoro.Event, 64)
+
+	go func() {
+		defer close(events)
+
+		for {
+			var e pomodoro.Event
+			if err := dec.Decode(&e); err != nil {
+				return
+			}
+			events <- e
+		}
+	}()
+
+	cancel := func() {
+		conn.Close()
+
+		// Let the reader goroutine finish.
+		for range events {
+		}
+	}
+
+	return events, cancel, nil
+}
+
+func (c *Client) call(action string) (pomodoro.Interval, error) {
+	return c.do(request{Action: action})
+}
+
+func (c *Client) do(req request) (pomodoro.Interval, error) {
+	conn, dec, err := c.send(req)
+	if err != nil {
+		return pomodoro.Interval{}, err
+	}
+	defer conn.Close()
+
+	resp, err := readResponse(dec)
+	if err != nil {
+		return pomodoro.Interval{}, err
+	}
+
+	if resp.Interval == nil {
+		return pomodoro.Interval{}, errors.New("daemon: empty response")
+	}
+
+	return *resp.Interval, nil
+}
+
+func (c *Client) send(req request) (net.Conn, *json.Decoder, error) {
+	conn, err := net.Dial("unix", c.path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, json.NewDecoder(bufio.NewReader(conn)), nil
+}
+
+func readResponse(dec *json.Decoder) (response, error) {
+	var resp response
+	if err := dec.Decode(&resp); err != nil {
+		return resp, err
+	}
+
+	if resp.Error != "" {
+		return resp, &remoteError{msg: resp.Error, target: errorCodes[resp.Code]}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,81 @@
+// Package daemon serves a pomodoro.Controller over a Unix socket, so that
+// the timer keeps running after the terminal that started it is closed.
+//
+// The protocol is line-delimited JSON. A client sends one request,
+//
+//	{"action": "start"}
+//
+// where action is status, start, resume, pause, end, skip, extend or
+// subscribe, and reads one response:
+//
+//	{"interval": {...}}
+//	{"error": "Interval not running", "code": "not_running"}
+//
+// extend takes the number of seconds to add in "seconds". A subscribe
+// request is answered with an empty response followed by one
+// pomodoro.Event per line until either side closes the connection.
+package daemon
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+var ErrUnknownAction = errors.New("Unknown action")
+
+type request struct {
+	Action  string  `json:"action"`
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+type response struct {
+	Interval *pomodoro.Interval `json:"interval,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Code     string             `json:"code,omitempty"`
+}
+
+// errorCodes carry the pomodoro sentinel errors across the socket so that
+// clients can still match them with errors.Is.
+var errorCodes = map[string]error{
+	"no_interval":      pomodoro.ErrNoInterval,
+	"not_running":      pomodoro.ErrIntervalNotRunning,
+	"completed":        pomodoro.ErrIntervalCompleted,
+	"invalid_state":    pomodoro.ErrInvalidState,
+	"invalid_id":       pomodoro.ErrInvalidID,
+	"invalid_duration": pomodoro.ErrInvalidDuration,
+	"unknown_action":   ErrUnknownAction,
+}
+
+func errorCode(err error) string {
+	for code, target := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// remoteError is an error returned by the daemon.
+type remoteError struct {
+	msg    string
+	target error
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.target }
+
+// DefaultSocket returns the socket path used when none is configured:
+// ztimer.sock in $XDG_RUNTIME_DIR, or a per-user file in the temporary
+// directory.
+func DefaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "ztimer.sock")
+	}
+
+	return filepath.Join(os.TempDir(), "ztimer-"+strconv.Itoa(os.Getuid())+".sock")
+}
@@ -0,0 +1,144 @@
+package daemon_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/daemon"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+func startDaemon(t *testing.T) (string, *pomodoro.Runner) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	repo, err := repository.Open("sqlite:" + filepath.Join(dir, "pomo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const duration = 2 * time.Second
+	runner := pomodoro.NewRunner(pomodoro.NewConfig(repo, duration, duration, duration))
+
+	socket := filepath.Join(dir, "d.sock")
+	ln, err := daemon.Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- daemon.NewServer(runner).Serve(ln) }()
+
+	t.Cleanup(func() {
+		ln.Close()
+		if err := <-served; err != nil {
+			t.Error(err)
+		}
+		runner.Close()
+	})
+
+	return socket, runner
+}
+
+func TestClient(t *testing.T) {
+	socket, _ := startDaemon(t)
+
+	if _, err := daemon.Listen(socket); err == nil {
+		t.Error("Expected Listen to refuse a socket a daemon is serving")
+	}
+
+	c, err := daemon.Dial(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Status(); !errors.Is(err, pomodoro.ErrNoInterval) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrNoInterval, err)
+	}
+
+	events, cancel, err := c.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	i, err := c.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i.State != pomodoro.StateRunning || i.Category != pomodoro.CategoryPomodoro {
+		t.Errorf("Expected a running %s, got %+v instead.\n", pomodoro.CategoryPomodoro, i)
+	}
+
+	if i, err = c.Extend(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if i.PlannedDuration != 3*time.Second {
+		t.Errorf("Expected planned duration %q, got %q instead.\n", 3*time.Second, i.PlannedDuration)
+	}
+
+	var types []string
+	timeout := time.After(5 * time.Second)
+
+	for len(types) == 0 || types[len(types)-1] != pomodoro.EventDone {
+		select {
+		case e := <-events:
+			if e.Interval.ID != i.ID {
+				t.Fatalf("Expected events for interval %d, got %+v", i.ID, e)
+			}
+			types = append(types, e.Type)
+		case <-timeout:
+			t.Fatalf("Timed out waiting for the interval to finish, got %v", types)
+		}
+	}
+
+	if types[0] != pomodoro.EventStarted || len(types) < 3 {
+		t.Errorf("Expected started, ticks and done, got %v instead.\n", types)
+	}
+
+	if _, err := c.Pause(); !errors.Is(err, pomodoro.ErrIntervalNotRunning) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrIntervalNotRunning, err)
+	}
+
+	next, err := c.Skip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.Category != pomodoro.CategoryPomodoro {
+		t.Errorf("Expected %s after skipping the break, got %s instead.\n",
+			pomodoro.CategoryPomodoro, next.Category)
+	}
+}
+
+func TestListenStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "d.sock")
+
+	ln, err := daemon.Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A closed unix listener removes its file, so leave one behind the way
+	// a crashed daemon would.
+	if l, ok := ln.(interface{ SetUnlinkOnClose(bool) }); ok {
+		l.SetUnlinkOnClose(false)
+	}
+	ln.Close()
+
+	if _, err := daemon.Dial(socket); err == nil {
+		t.Fatal("Expected no daemon to answer")
+	}
+
+	ln, err = daemon.Listen(socket)
+	if err != nil {
+		t.Fatalf("Expected the stale socket to be replaced, got %q", err)
+	}
+	ln.Close()
+}
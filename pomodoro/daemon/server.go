@@ -0,0 +1,176 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// Listen listens on the Unix socket at path. A socket file left behind by a
+// daemon that is gone is replaced, but a live daemon is never displaced.
+func Listen(path string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("daemon already listening on %s", path)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	return ln, nil
+}
+
+// Server answers requests on a listener with a Controller.
+type Server struct {
+	ctl pomodoro.Controller
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func NewServer(ctl pomodoro.Controller) *Server {
+	return &Server{
+		ctl:   ctl,
+		conns: map[net.Conn]struct{}{},
+	}
+}
+
+// Serve handles connections until ln is closed. Closing the listener also
+// ends the open subscriptions.
+func (s *Server) Serve(ln net.Listener) error {
+	defer s.closeConns()
+
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		go func() {
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+
+				conn.Close()
+			}()
+
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	var req request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		return
+	}
+
+	enc := json.NewEncoder(conn)
+
+	if req.Action == "subscribe" {
+		s.subscribe(conn, enc)
+		return
+	}
+
+	var (
+		i   pomodoro.Interval
+		err error
+	)
+
+	switch req.Action {
+	case "status":
+		i, err = s.ctl.Status()
+	case "start":
+		i, err = s.ctl.Start()
+	case "resume":
+		i, err = s.ctl.Resume()
+	case "pause":
+		i, err = s.ctl.Pause()
+	case "end":
+		i, err = s.ctl.End()
+	case "skip":
+		i, err = s.ctl.Skip()
+	case "extend":
+		i, err = s.ctl.Extend(time.Duration(req.Seconds * float64(time.Second)))
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnknownAction, req.Action)
+	}
+
+	if err != nil {
+		enc.Encode(response{Error: err.Error(), Code: errorCode(err)})
+		return
+	}
+
+	enc.Encode(response{Interval: &i})
+}
+
+// subscribe streams events to conn until the client hangs up or the
+// subscription ends.
+func (s *Server) subscribe(conn net.Conn, enc *json.Encoder) {
+	events, cancel, err := s.ctl.Subscribe()
+	if err != nil {
+		enc.Encode(response{Error: err.Error(), Code: errorCode(err)})
+		return
+	}
+	defer cancel()
+
+	if err := enc.Encode(response{}); err != nil {
+		return
+	}
+
+	// Clients send nothing more, so a read returns once they hang up.
+	gone := make(chan struct{})
+	go func() {
+		conn.Read(make([]byte, 1))
+		close(gone)
+	}()
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := enc.Encode(e); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
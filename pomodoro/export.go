@@ -0,0 +1,299 @@
+package pomodoro
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnknownFormat = errors.New("Unknown export format")
+
+var stateNames = []string{
+	StateNotStarted: "not_started",
+	StateRunning:    "running",
+	StatePaused:     "paused",
+	StateDone:       "done",
+	StateCancelled:  "cancelled",
+}
+
+// StateName returns the name used for a state in exports and JSON output.
+func StateName(state int) string {
+	if state < 0 || state >= len(stateNames) {
+		return strconv.Itoa(state)
+	}
+
+	return stateNames[state]
+}
+
+// ParseState is the inverse of StateName.
+func ParseState(name string) (int, error) {
+	for state, n := range stateNames {
+		if n == name {
+			return state, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidState, name)
+}
+
+// IntervalJSON is the stable JSON schema of an Interval in exports and in
+// what the CLI, the HTTP API, hooks and webhooks print or send:
+//
+//	id               integer
+//	category         "Pomodoro", "ShortBreak" or "LongBreak"
+//	state            "not_started", "running", "paused", "done" or "cancelled"
+//	start_time       RFC 3339 timestamp, null when not started
+//	end_time         start_time plus actual_seconds, null when not started
+//	planned_seconds  number
+//	actual_seconds   number
+//
+// Interval itself keeps the default encoding, which the daemon protocol
+// relies on.
+type IntervalJSON struct {
+	ID             int64      `json:"id"`
+	Category       string     `json:"category"`
+	State          string     `json:"state"`
+	StartTime      *time.Time `json:"start_time"`
+	EndTime        *time.Time `json:"end_time"`
+	PlannedSeconds float64    `json:"planned_seconds"`
+	ActualSeconds  float64    `json:"actual_seconds"`
+}
+
+func NewIntervalJSON(i Interval) IntervalJSON {
+	v := IntervalJSON{
+		ID:             i.ID,
+		Category:       i.Category,
+		State:          StateName(i.State),
+		PlannedSeconds: i.PlannedDuration.Seconds(),
+		ActualSeconds:  i.ActualDuration.Seconds(),
+	}
+
+	if !i.StartTime.IsZero() {
+		start, end := i.StartTime, i.EndTime()
+		v.StartTime, v.EndTime = &start, &end
+	}
+
+	return v
+}
+
+// IntervalsJSON converts intervals to the IntervalJSON schema, as an empty
+// list rather than null when there are none.
+func IntervalsJSON(intervals []Interval) []IntervalJSON {
+	vs := make([]IntervalJSON, 0, len(intervals))
+	for _, i := range intervals {
+		vs = append(vs, NewIntervalJSON(i))
+	}
+
+	return vs
+}
+
+// EventJSON is an Event with its interval in the IntervalJSON schema.
+type EventJSON struct {
+	Seq       uint64       `json:"seq"`
+	Type      string       `json:"type"`
+	Time      time.Time    `json:"time"`
+	Interval  IntervalJSON `json:"interval"`
+	Milestone *Milestone   `json:"milestone,omitempty"`
+	Idle      *Idle        `json:"idle,omitempty"`
+}
+
+func NewEventJSON(e Event) EventJSON {
+	return EventJSON{
+		Seq:       e.Seq,
+		Type:      e.Type,
+		Time:      e.Time,
+		Interval:  NewIntervalJSON(e.Interval),
+		Milestone: e.Milestone,
+		Idle:      e.Idle,
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Microsecond)
+}
+
+// History returns the started intervals with a start time in [from, to).
+func History(config *IntervalConfig, from, to time.Time) ([]Interval, error) {
+	return config.repo.Range(from, to)
+}
+
+// Exporter writes intervals in a file format.
+type Exporter interface {
+	Export(w io.Writer, intervals []Interval) error
+}
+
+var exporters = map[string]Exporter{
+	"csv":  CSVExporter{},
+	"json": JSONExporter{},
+	"ics":  ICSExporter{},
+}
+
+// RegisterExporter makes an exporter available to NewExporter.
+func RegisterExporter(format string, e Exporter) {
+	exporters[format] = e
+}
+
+// ExportFormats returns the sorted names of the registered formats.
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for f := range exporters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// NewExporter returns the exporter registered for format.
+func NewExporter(format string) (Exporter, error) {
+	e, ok := exporters[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)",
+			ErrUnknownFormat, format, strings.Join(ExportFormats(), ", "))
+	}
+
+	return e, nil
+}
+
+// CSVExporter writes a header line followed by one row per interval, with
+// the columns:
+//
+//	id,category,state,start_time,end_time,planned_seconds,actual_seconds
+//
+// Times are RFC 3339 in local time and durations are whole seconds.
+type CSVExporter struct{}
+
+var csvHeader = []string{
+	"id", "category", "state", "start_time", "end_time",
+	"planned_seconds", "actual_seconds",
+}
+
+func (CSVExporter) Export(w io.Writer, intervals []Interval) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, i := range intervals {
+		var start, end string
+		if !i.StartTime.IsZero() {
+			start = i.StartTime.Local().Format(time.RFC3339)
+			end = i.EndTime().Local().Format(time.RFC3339)
+		}
+
+		err := cw.Write([]string{
+			strconv.FormatInt(i.ID, 10),
+			i.Category,
+			StateName(i.State),
+			start,
+			end,
+			strconv.FormatInt(int64(i.PlannedDuration.Seconds()), 10),
+			strconv.FormatInt(int64(i.ActualDuration.Seconds()), 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// JSONExporter writes a JSON array of intervals in the IntervalJSON schema.
+type JSONExporter struct{}
+
+func (JSONExporter) Export(w io.Writer, intervals []Interval) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(IntervalsJSON(intervals))
+}
+
+// ICSExporter writes an iCalendar file with one VEVENT per completed
+// pomodoro. Breaks and unfinished intervals are left out.
+type ICSExporter struct{}
+
+func (ICSExporter) Export(w io.Writer, intervals []Interval) error {
+	const stamp = "20060102T150405Z"
+
+	// DTSTAMP is when the events were written, the time of the export.
+	now := time.Now().UTC()
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//ZeroBl21//go-ztimer//EN",
+		"CALSCALE:GREGORIAN",
+	}
+
+	for _, i := range intervals {
+		if i.Category != CategoryPomodoro || i.State != StateDone {
+			continue
+		}
+
+		start := i.StartTime.UTC()
+		end := i.EndTime().UTC()
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%d-%d@go-ztimer", i.ID, start.Unix()),
+			"DTSTAMP:"+now.Format(stamp),
+			"DTSTART:"+start.Format(stamp),
+			"DTEND:"+end.Format(stamp),
+			"SUMMARY:"+icsEscape(i.Category),
+			"DESCRIPTION:"+icsEscape(fmt.Sprintf("Planned %s, focused %s",
+				i.PlannedDuration, i.ActualDuration)),
+			"CATEGORIES:"+icsEscape(i.Category),
+			"END:VEVENT",
+		)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, l := range lines {
+		if _, err := io.WriteString(w, icsFold(l)+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func icsEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// icsFold splits content lines longer than 75 octets as RFC 5545 requires,
+// without cutting through a UTF-8 sequence.
+func icsFold(line string) string {
+	const limit = 75
+
+	var b strings.Builder
+	n := 0
+
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+
+		b.WriteRune(r)
+		n += size
+	}
+
+	return b.String()
+}
@@ -0,0 +1,178 @@
+package pomodoro_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func exportIntervals() []pomodoro.Interval {
+	start := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.UTC)
+
+	return []pomodoro.Interval{
+		{
+			ID: 1, StartTime: start, Category: pomodoro.CategoryPomodoro,
+			PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+			State: pomodoro.StateDone,
+		},
+		{
+			ID: 2, StartTime: start.Add(25 * time.Minute), Category: pomodoro.CategoryShortBreak,
+			PlannedDuration: 5 * time.Minute, ActualDuration: 5 * time.Minute,
+			State: pomodoro.StateDone,
+		},
+		{
+			ID: 3, StartTime: start.Add(30 * time.Minute), Category: pomodoro.CategoryPomodoro,
+			PlannedDuration: 25 * time.Minute, ActualDuration: 10 * time.Minute,
+			State: pomodoro.StateCancelled,
+		},
+	}
+}
+
+func TestNewExporter(t *testing.T) {
+	for _, format := range []string{"csv", "json", "ics", "CSV"} {
+		if _, err := pomodoro.NewExporter(format); err != nil {
+			t.Errorf("Expected no error for %q, got %q.\n", format, err)
+		}
+	}
+
+	if _, err := pomodoro.NewExporter("xml"); !errors.Is(err, pomodoro.ErrUnknownFormat) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrUnknownFormat, err)
+	}
+}
+
+func TestCSVExporter(t *testing.T) {
+	intervals := exportIntervals()
+
+	var buf bytes.Buffer
+	if err := (pomodoro.CSVExporter{}).Export(&buf, intervals); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := func(tm time.Time) string {
+		return tm.Local().Format(time.RFC3339)
+	}
+
+	exp := "id,category,state,start_time,end_time,planned_seconds,actual_seconds\n" +
+		"1,Pomodoro,done," + ts(intervals[0].StartTime) + "," + ts(intervals[0].EndTime()) + ",1500,1500\n" +
+		"2,ShortBreak,done," + ts(intervals[1].StartTime) + "," + ts(intervals[1].EndTime()) + ",300,300\n" +
+		"3,Pomodoro,cancelled," + ts(intervals[2].StartTime) + "," + ts(intervals[2].EndTime()) + ",1500,600\n"
+
+	if buf.String() != exp {
+		t.Errorf("Expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	intervals := append(exportIntervals(), pomodoro.Interval{
+		ID: 4, Category: pomodoro.CategoryLongBreak, PlannedDuration: 15 * time.Minute,
+	})
+
+	var buf bytes.Buffer
+	if err := (pomodoro.JSONExporter{}).Export(&buf, intervals); err != nil {
+		t.Fatal(err)
+	}
+
+	var raw []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw[0]["state"] != "done" || raw[0]["planned_seconds"] != 1500.0 {
+		t.Errorf("Unexpected schema for first interval: %v", raw[0])
+	}
+
+	if raw[3]["start_time"] != nil || raw[3]["end_time"] != nil {
+		t.Errorf("Expected null times for an interval never started, got %v", raw[3])
+	}
+
+	var got []pomodoro.IntervalJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, i := range intervals {
+		exp := pomodoro.NewIntervalJSON(i)
+
+		if got[k].ID != exp.ID ||
+			got[k].Category != exp.Category ||
+			got[k].State != exp.State ||
+			(got[k].StartTime == nil) != (exp.StartTime == nil) ||
+			(exp.StartTime != nil && !got[k].StartTime.Equal(*exp.StartTime)) ||
+			got[k].PlannedSeconds != exp.PlannedSeconds ||
+			got[k].ActualSeconds != exp.ActualSeconds {
+			t.Errorf("Expected %+v, got %+v instead.\n", exp, got[k])
+		}
+	}
+}
+
+func TestIntervalDefaultJSON(t *testing.T) {
+	// The daemon protocol relies on the default encoding, which keeps
+	// states the schema has no name for.
+	exp := exportIntervals()[0]
+	exp.State = 42
+
+	data, err := json.Marshal(exp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got pomodoro.Interval
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.State != exp.State || got.PlannedDuration != exp.PlannedDuration || !got.StartTime.Equal(exp.StartTime) {
+		t.Errorf("Expected %+v, got %+v instead.\n", exp, got)
+	}
+}
+
+func TestICSExporter(t *testing.T) {
+	var buf bytes.Buffer
+
+	before := time.Now().UTC().Truncate(time.Second)
+	if err := (pomodoro.ICSExporter{}).Export(&buf, exportIntervals()); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().UTC()
+
+	out := buf.String()
+
+	_, rest, ok := strings.Cut(out, "DTSTAMP:")
+	if !ok {
+		t.Fatal("Expected a DTSTAMP line")
+	}
+	stamp, err := time.Parse("20060102T150405Z", rest[:16])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stamp.Before(before) || stamp.After(after) {
+		t.Errorf("Expected DTSTAMP at export time, got %s instead.\n", stamp)
+	}
+
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != 1 {
+		t.Errorf("Expected 1 event for the completed pomodoro, got %d.\n", n)
+	}
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20250310T090000Z\r\n",
+		"DTEND:20250310T092500Z\r\n",
+		"SUMMARY:Pomodoro\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected output to contain %q", line)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines folded at 75 octets, got %d: %q", len(line), line)
+		}
+	}
+}
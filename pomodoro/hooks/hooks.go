@@ -0,0 +1,224 @@
+// Package hooks runs user commands on timer events, such as turning on Do
+// Not Disturb when a pomodoro starts.
+//
+// Each command runs through the shell with the interval in ZTIMER_*
+// environment variables and the pomodoro.Event as JSON on stdin. Commands
+// run in the background, so a slow hook never holds up the timer.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultConcurrency = 4
+)
+
+var ErrUnknownEvent = errors.New("Unknown event")
+
+// Events lists the event types hooks can be attached to.
+var Events = []string{
+	pomodoro.EventStarted,
+	pomodoro.EventTick,
+	pomodoro.EventPaused,
+	pomodoro.EventDone,
+	pomodoro.EventCancelled,
+	pomodoro.EventMilestone,
+	pomodoro.EventIdle,
+}
+
+// Config maps event types to the shell commands run for them.
+type Config struct {
+	Commands map[string][]string
+	// Timeout kills a command that runs longer. Zero means DefaultTimeout.
+	Timeout time.Duration
+	// Concurrency caps the commands running at once. Zero means
+	// DefaultConcurrency.
+	Concurrency int
+}
+
+// Validate reports event types that hooks cannot be attached to.
+func (c Config) Validate() error {
+	for event := range c.Commands {
+		known := false
+		for _, e := range Events {
+			known = known || e == event
+		}
+
+		if !known {
+			return fmt.Errorf("%w: %q, expected one of %s",
+				ErrUnknownEvent, event, strings.Join(Events, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Hooks runs the commands of a Config. Failures, timeouts and skipped runs
+// are written to its logger.
+type Hooks struct {
+	config Config
+	log    *log.Logger
+	sem    chan struct{}
+	wg     sync.WaitGroup
+}
+
+func New(config Config, logOut io.Writer) (*Hooks, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+
+	return &Hooks{
+		config: config,
+		log:    log.New(logOut, "hook: ", log.LstdFlags),
+		sem:    make(chan struct{}, config.Concurrency),
+	}, nil
+}
+
+// Follow runs the hooks for the events of ctl until the subscription ends.
+func (h *Hooks) Follow(ctl pomodoro.Controller) error {
+	events, _, err := ctl.Subscribe()
+	if err != nil {
+		return err
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		for e := range events {
+			h.Dispatch(e)
+		}
+	}()
+
+	return nil
+}
+
+// Dispatch starts the commands for e and returns without waiting for them.
+// When the concurrency limit is reached, tick hooks are skipped, while the
+// others wait for a free slot.
+func (h *Hooks) Dispatch(e pomodoro.Event) {
+	for _, command := range h.config.Commands[e.Type] {
+		if e.Type == pomodoro.EventTick {
+			select {
+			case h.sem <- struct{}{}:
+			default:
+				h.log.Printf("%s: skipped %q, %d hooks already running",
+					e.Type, command, h.config.Concurrency)
+				continue
+			}
+		}
+
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+
+			if e.Type != pomodoro.EventTick {
+				h.sem <- struct{}{}
+			}
+			defer func() { <-h.sem }()
+
+			if err := h.run(command, e); err != nil {
+				h.log.Printf("%s: %q: %v", e.Type, command, err)
+			}
+		}()
+	}
+}
+
+// Wait blocks until the subscription followed and every running command
+// ended.
+func (h *Hooks) Wait() {
+	h.wg.Wait()
+}
+
+func (h *Hooks) run(command string, e pomodoro.Event) error {
+	input, err := json.Marshal(pomodoro.NewEventJSON(e))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Env = append(os.Environ(), Env(e)...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Do not wait for children that kept the output open.
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return fmt.Errorf("timed out after %s", h.config.Timeout)
+	case err != nil && out.Len() > 0:
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(out.String()))
+	}
+
+	return err
+}
+
+// Env returns the environment variables describing e to a hook.
+func Env(e pomodoro.Event) []string {
+	i := e.Interval
+
+	start := ""
+	if !i.StartTime.IsZero() {
+		start = i.StartTime.Format(time.RFC3339)
+	}
+
+	env := []string{
+		"ZTIMER_EVENT=" + e.Type,
+		"ZTIMER_SEQ=" + strconv.FormatUint(e.Seq, 10),
+		"ZTIMER_ID=" + strconv.FormatInt(i.ID, 10),
+		"ZTIMER_CATEGORY=" + i.Category,
+		"ZTIMER_STATE=" + pomodoro.StateName(i.State),
+		"ZTIMER_START_TIME=" + start,
+		"ZTIMER_PLANNED_SECONDS=" + seconds(i.PlannedDuration),
+		"ZTIMER_ACTUAL_SECONDS=" + seconds(i.ActualDuration),
+		"ZTIMER_REMAINING_SECONDS=" + seconds(i.Remaining()),
+	}
+
+	if e.Milestone != nil {
+		env = append(env, "ZTIMER_MILESTONE="+e.Milestone.Message)
+	}
+
+	if e.Idle != nil {
+		env = append(env,
+			"ZTIMER_IDLE_SINCE="+e.Idle.Since.Format(time.RFC3339),
+			"ZTIMER_REMINDERS="+strconv.Itoa(e.Idle.Reminders),
+		)
+	}
+
+	return env
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(d.Seconds()))
+}
@@ -0,0 +1,187 @@
+package hooks_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/hooks"
+)
+
+// syncBuffer is a log that the hooks may write concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func testEvent(typ string) pomodoro.Event {
+	return pomodoro.Event{
+		Seq:  7,
+		Type: typ,
+		Time: time.Now(),
+		Interval: pomodoro.Interval{
+			ID:              3,
+			StartTime:       time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+			PlannedDuration: 25 * time.Minute,
+			ActualDuration:  10 * time.Minute,
+			Category:        pomodoro.CategoryPomodoro,
+			State:           pomodoro.StateRunning,
+		},
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "env")
+	stdinFile := filepath.Join(dir, "stdin")
+
+	var log syncBuffer
+	h, err := hooks.New(hooks.Config{
+		Commands: map[string][]string{
+			pomodoro.EventStarted: {
+				"env | grep ^ZTIMER_ | sort > " + envFile,
+				"cat > " + stdinFile,
+			},
+			pomodoro.EventDone: {"exit 3"},
+		},
+	}, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Dispatch(testEvent(pomodoro.EventStarted))
+	h.Dispatch(testEvent(pomodoro.EventDone))
+	h.Dispatch(testEvent(pomodoro.EventPaused))
+	h.Wait()
+
+	env, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expEnv := []string{
+		"ZTIMER_ACTUAL_SECONDS=600",
+		"ZTIMER_CATEGORY=Pomodoro",
+		"ZTIMER_EVENT=started",
+		"ZTIMER_ID=3",
+		"ZTIMER_PLANNED_SECONDS=1500",
+		"ZTIMER_REMAINING_SECONDS=900",
+		"ZTIMER_SEQ=7",
+		"ZTIMER_START_TIME=2025-03-10T09:00:00Z",
+		"ZTIMER_STATE=running",
+	}
+	if got := strings.Fields(string(env)); strings.Join(got, " ") != strings.Join(expEnv, " ") {
+		t.Errorf("Expected env %q, got %q instead.\n", expEnv, got)
+	}
+
+	stdin, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e pomodoro.EventJSON
+	if err := json.Unmarshal(stdin, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != pomodoro.EventStarted || e.Interval.ID != 3 {
+		t.Errorf("Expected started event of interval 3, got %s of %d instead.\n",
+			e.Type, e.Interval.ID)
+	}
+
+	if !strings.Contains(log.String(), `done: "exit 3": exit status 3`) {
+		t.Errorf("Expected the failing hook to be logged, got %q instead.\n", log.String())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var log syncBuffer
+	h, err := hooks.New(hooks.Config{
+		Commands: map[string][]string{pomodoro.EventStarted: {"sleep 10"}},
+		Timeout:  100 * time.Millisecond,
+	}, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	h.Dispatch(testEvent(pomodoro.EventStarted))
+	h.Wait()
+
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Expected the hook to be killed, it ran for %s.\n", d)
+	}
+	if !strings.Contains(log.String(), "timed out after 100ms") {
+		t.Errorf("Expected a timeout to be logged, got %q instead.\n", log.String())
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	testCases := []struct {
+		name    string
+		event   string
+		expRuns int
+	}{
+		{name: "TickSkipped", event: pomodoro.EventTick, expRuns: 1},
+		{name: "OthersWait", event: pomodoro.EventPaused, expRuns: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runs := filepath.Join(t.TempDir(), "runs")
+
+			var log syncBuffer
+			h, err := hooks.New(hooks.Config{
+				Commands:    map[string][]string{tc.event: {"echo x >> " + runs + "; sleep 0.2"}},
+				Concurrency: 1,
+			}, &log)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for range 3 {
+				h.Dispatch(testEvent(tc.event))
+			}
+			h.Wait()
+
+			out, err := os.ReadFile(runs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n := strings.Count(string(out), "x"); n != tc.expRuns {
+				t.Errorf("Expected %d runs, got %d instead.\n", tc.expRuns, n)
+			}
+		})
+	}
+}
+
+func TestUnknownEvent(t *testing.T) {
+	_, err := hooks.New(hooks.Config{
+		Commands: map[string][]string{"finished": {"true"}},
+	}, &syncBuffer{})
+
+	if !errors.Is(err, hooks.ErrUnknownEvent) {
+		t.Errorf("Expected error %q, got %v instead.\n", hooks.ErrUnknownEvent, err)
+	}
+}
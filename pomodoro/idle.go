@@ -0,0 +1,292 @@
+package pomodoro
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventIdle is published by a Runner with reminders set while no interval
+// runs.
+const EventIdle = "idle"
+
+// defaultIdlePoll is how often a Runner checks whether the timer is idle.
+// Tests shorten it.
+var defaultIdlePoll = time.Second
+
+var ErrInvalidWindow = errors.New("Invalid time window")
+
+// Idle describes the time since the last interval finished.
+type Idle struct {
+	Since time.Time `json:"since"`
+	// Reminders counts the reminders sent so far, including this one.
+	Reminders int `json:"reminders"`
+}
+
+// Gap is a stretch of time between two intervals with neither running.
+type Gap struct {
+	ID        int64
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+func (g Gap) Duration() time.Duration {
+	return g.EndTime.Sub(g.StartTime)
+}
+
+// GapRecorder is implemented by repositories that keep the idle gaps
+// between intervals.
+type GapRecorder interface {
+	// CreateGap stores g and returns its ID. A gap overlapping one already
+	// stored is ignored with ID 0, so that several processes watching the
+	// same repository record it once.
+	CreateGap(g Gap) (int64, error)
+	// Gaps returns the gaps whose start time falls in [from, to), ordered
+	// by start time.
+	Gaps(from, to time.Time) ([]Gap, error)
+}
+
+// IdleSummary returns the idle time recorded on the local day of day, or
+// zero when the repository does not record gaps.
+func IdleSummary(day time.Time, config *IntervalConfig) (time.Duration, error) {
+	gr, ok := config.repo.(GapRecorder)
+	if !ok {
+		return 0, nil
+	}
+
+	y, m, d := day.Local().Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+
+	gaps, err := gr.Gaps(from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	var total time.Duration
+	for _, g := range gaps {
+		total += g.Duration()
+	}
+
+	return total, nil
+}
+
+// Window is a daily stretch of local time, such as 09:00-17:00, that wraps
+// around midnight when it ends before it starts. The zero Window is empty.
+type Window struct {
+	From, To time.Duration
+}
+
+// ParseWindow parses "HH:MM-HH:MM". An empty string is the zero Window.
+func ParseWindow(s string) (Window, error) {
+	if s == "" {
+		return Window{}, nil
+	}
+
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("%w: %q, expected HH:MM-HH:MM", ErrInvalidWindow, s)
+	}
+
+	var w Window
+	var err error
+
+	if w.From, err = parseClock(from); err != nil {
+		return w, fmt.Errorf("%w: %q: %w", ErrInvalidWindow, s, err)
+	}
+	if w.To, err = parseClock(to); err != nil {
+		return w, fmt.Errorf("%w: %q: %w", ErrInvalidWindow, s, err)
+	}
+
+	if w.From == w.To {
+		return w, fmt.Errorf("%w: %q is empty", ErrInvalidWindow, s)
+	}
+
+	return w, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (w Window) IsZero() bool {
+	return w == Window{}
+}
+
+// Contains reports whether the local time of t falls in w.
+func (w Window) Contains(t time.Time) bool {
+	t = t.Local()
+	clock := time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+
+	if w.From <= w.To {
+		return w.From <= clock && clock < w.To
+	}
+
+	return clock >= w.From || clock < w.To
+}
+
+// ReminderPolicy sets how a Runner reminds that no interval is running
+// after one finished.
+type ReminderPolicy struct {
+	// Every is the time between reminders. Zero disables them.
+	Every time.Duration
+	// WorkHours limits reminders to working hours, or allows them at any
+	// time when zero.
+	WorkHours Window
+	// QuietHours silences reminders, even during working hours.
+	QuietHours Window
+}
+
+// Validate reports a negative time between reminders.
+func (p ReminderPolicy) Validate() error {
+	if p.Every < 0 {
+		return fmt.Errorf("%w: reminders every %s", ErrInvalidDuration, p.Every)
+	}
+
+	return nil
+}
+
+// allows reports whether a reminder may be sent at t.
+func (p ReminderPolicy) allows(t time.Time) bool {
+	if p.Every == 0 {
+		return false
+	}
+
+	if !p.WorkHours.IsZero() && !p.WorkHours.Contains(t) {
+		return false
+	}
+
+	return !p.QuietHours.Contains(t)
+}
+
+// idleState is what a Runner knows of the time since the last interval
+// finished.
+type idleState struct {
+	Idle
+	// observed is set when the runner saw the interval before the gap
+	// finish, so that the start of the gap is known.
+	observed bool
+	// active is set while an interval is running or paused.
+	active bool
+	// known is set once the runner checked the timer.
+	known bool
+	// last is when the latest reminder was sent.
+	last time.Time
+}
+
+// SetReminders sets the reminders published as EventIdle while no
+// interval runs.
+func (r *Runner) SetReminders(p ReminderPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	r.idleMu.Lock()
+	defer r.idleMu.Unlock()
+
+	r.reminders = p
+
+	return nil
+}
+
+// watchIdle follows the state of the timer to record the gaps between
+// intervals started by other processes and send reminders, until the
+// runner is closed.
+func (r *Runner) watchIdle() {
+	ticker := time.NewTicker(r.idlePoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			if err := r.checkIdle(now); err != nil {
+				r.reportError(err)
+			}
+		case <-r.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkIdle polls the timer. The lock is held while reading it so that a
+// stale read cannot undo what the runner recorded meanwhile.
+func (r *Runner) checkIdle(now time.Time) error {
+	r.idleMu.Lock()
+	defer r.idleMu.Unlock()
+
+	i, err := Current(r.config)
+	if errors.Is(err, ErrNoInterval) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s := &r.idle
+	active := i.State == StateRunning || i.State == StatePaused
+
+	switch {
+	case active && !s.active:
+		return r.endIdle(now)
+
+	case !active && (s.active || !s.known):
+		r.beginIdle(now, s.active)
+
+	case !active && r.reminders.allows(now) && now.Sub(s.last) >= r.reminders.Every:
+		s.last = now
+		s.Reminders++
+
+		// Subscribers never block, so the event is published under the
+		// lock.
+		idle := s.Idle
+		r.publishEvent(Event{Type: EventIdle, Interval: i, Idle: &idle})
+	}
+
+	return nil
+}
+
+// beginIdle marks the timer idle from now. observed tells whether the
+// interval before was seen finishing, so that the gap starts at now. The
+// caller holds r.idleMu.
+func (r *Runner) beginIdle(now time.Time, observed bool) {
+	r.idle = idleState{
+		Idle:     Idle{Since: now},
+		observed: observed,
+		known:    true,
+		last:     now,
+	}
+}
+
+// endIdle marks an interval active from now, and records the gap before it
+// when its start is known. Gaps shorter than a poll are the time between
+// an interval finishing and the next one starting right away. The caller
+// holds r.idleMu.
+func (r *Runner) endIdle(now time.Time) error {
+	s := r.idle
+	r.idle = idleState{active: true, known: true}
+
+	if s.active || !s.observed {
+		return nil
+	}
+
+	gap := Gap{StartTime: s.Since, EndTime: now}
+	if gap.Duration() < r.idlePoll {
+		return nil
+	}
+
+	gr, ok := r.config.repo.(GapRecorder)
+	if !ok {
+		return nil
+	}
+
+	_, err := gr.CreateGap(gap)
+
+	return err
+}
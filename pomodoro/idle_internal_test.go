@@ -0,0 +1,12 @@
+package pomodoro
+
+import "time"
+
+// SetIdlePoll makes the runners created afterwards check the timer every d,
+// and returns a function restoring the default.
+func SetIdlePoll(d time.Duration) func() {
+	old := defaultIdlePoll
+	defaultIdlePoll = d
+
+	return func() { defaultIdlePoll = old }
+}
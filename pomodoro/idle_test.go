@@ -0,0 +1,51 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func TestWindow(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2025, 3, 10, hour, min, 0, 0, time.Local)
+	}
+
+	testCases := []struct {
+		name   string
+		window string
+		t      time.Time
+		exp    bool
+		expErr error
+	}{
+		{name: "Inside", window: "09:00-17:30", t: at(12, 0), exp: true},
+		{name: "Start", window: "09:00-17:30", t: at(9, 0), exp: true},
+		{name: "End", window: "09:00-17:30", t: at(17, 30), exp: false},
+		{name: "Before", window: "09:00-17:30", t: at(8, 59), exp: false},
+		{name: "WrapLate", window: "22:00-07:00", t: at(23, 15), exp: true},
+		{name: "WrapEarly", window: "22:00-07:00", t: at(6, 0), exp: true},
+		{name: "WrapOutside", window: "22:00-07:00", t: at(12, 0), exp: false},
+		{name: "Empty", window: "", t: at(12, 0), exp: false},
+		{name: "NoDash", window: "09:00", expErr: pomodoro.ErrInvalidWindow},
+		{name: "BadClock", window: "9am-5pm", expErr: pomodoro.ErrInvalidWindow},
+		{name: "Same", window: "09:00-09:00", expErr: pomodoro.ErrInvalidWindow},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := pomodoro.ParseWindow(tc.window)
+			if !errors.Is(err, tc.expErr) {
+				t.Fatalf("Expected error %v, got %v instead.\n", tc.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if got := w.Contains(tc.t); got != tc.exp {
+				t.Errorf("Expected %t for %s, got %t instead.\n", tc.exp, tc.t.Format("15:04"), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,410 @@
+package pomodoro
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownParser = errors.New("Unknown import format")
+	ErrMissingColumn = errors.New("Missing column")
+)
+
+// ImportRow is one record read by a Parser. Rows that could not be mapped
+// onto an interval carry Err instead.
+type ImportRow struct {
+	Line      int
+	Interval  Interval
+	Err       error
+	Duplicate bool
+}
+
+// ImportSummary counts the outcome of an Import.
+type ImportSummary struct {
+	Imported   int
+	Duplicates int
+	Errors     int
+}
+
+// Parser maps the records of another tool's export onto intervals.
+type Parser interface {
+	Parse(r io.Reader) ([]ImportRow, error)
+}
+
+var parsers = map[string]Parser{
+	"generic": GenericCSVParser{},
+	"toggl":   TogglParser{},
+}
+
+// RegisterParser makes a parser available to NewParser.
+func RegisterParser(format string, p Parser) {
+	parsers[format] = p
+}
+
+// ImportFormats returns the sorted names of the registered parsers.
+func ImportFormats() []string {
+	formats := make([]string, 0, len(parsers))
+	for f := range parsers {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// NewParser returns the parser registered for format.
+func NewParser(format string) (Parser, error) {
+	p, ok := parsers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)",
+			ErrUnknownParser, format, strings.Join(ImportFormats(), ", "))
+	}
+
+	return p, nil
+}
+
+// Import stores the valid rows that do not overlap an existing interval or
+// an earlier row, marking the rest as duplicates. With dryRun nothing is
+// written, but rows are checked and marked the same way.
+//
+// Intervals are ordered by ID, so the latest interval is rewritten after
+// the imported ones: an unfinished interval stays the one GetInterval
+// resumes, and a finished one keeps deciding the next category unless an
+// imported interval started after it. The row it leaves is reused for an
+// imported interval. Importing while an interval is running is refused.
+func Import(config *IntervalConfig, rows []ImportRow, dryRun bool) (ImportSummary, error) {
+	var sum ImportSummary
+
+	from, to := time.Time{}, time.Time{}
+	for _, r := range rows {
+		if r.Err != nil {
+			continue
+		}
+
+		if from.IsZero() || r.Interval.StartTime.Before(from) {
+			from = r.Interval.StartTime
+		}
+		if end := r.Interval.EndTime(); end.After(to) {
+			to = end
+		}
+	}
+
+	var existing []Interval
+	if !from.IsZero() {
+		// Intervals starting up to a day earlier may still overlap.
+		var err error
+		if existing, err = config.repo.Range(from.AddDate(0, 0, -1), to); err != nil {
+			return sum, err
+		}
+	}
+
+	last, err := config.repo.Last()
+	if err != nil && err != ErrNoInterval {
+		return sum, err
+	}
+	hasLast := err == nil
+	active := hasLast && last.State != StateDone && last.State != StateCancelled
+
+	if active && last.State == StateRunning {
+		return sum, fmt.Errorf("%w: stop interval %d before importing",
+			ErrInvalidState, last.ID)
+	}
+
+	var imported []*ImportRow
+
+	for k := range rows {
+		r := &rows[k]
+
+		if r.Err != nil {
+			sum.Errors++
+			continue
+		}
+
+		for _, e := range existing {
+			if r.Interval.Overlaps(e) {
+				r.Duplicate = true
+				break
+			}
+		}
+
+		if r.Duplicate {
+			sum.Duplicates++
+			continue
+		}
+
+		existing = append(existing, r.Interval)
+		imported = append(imported, r)
+		sum.Imported++
+	}
+
+	if dryRun || len(imported) == 0 {
+		return sum, nil
+	}
+
+	return sum, writeImported(config, imported, last, hasLast, active)
+}
+
+// writeImported stores the imported rows, setting their IDs, and rewrites
+// last after them. Finished intervals are written in start time order.
+func writeImported(config *IntervalConfig, imported []*ImportRow, last Interval, hasLast, active bool) error {
+	write := make([]*Interval, 0, len(imported)+1)
+	for _, r := range imported {
+		write = append(write, &r.Interval)
+	}
+
+	if hasLast {
+		write = append(write, &last)
+	}
+
+	// The unfinished interval stays last whatever its start time.
+	finished := write
+	if active {
+		finished = write[:len(write)-1]
+	}
+	sort.SliceStable(finished, func(a, b int) bool {
+		return finished[a].StartTime.Before(finished[b].StartTime)
+	})
+
+	// The first interval takes the row of last, so that it is not left
+	// behind as a copy.
+	first := 0
+	if hasLast {
+		write[0].ID = last.ID
+		first = 1
+	}
+
+	var err error
+	for _, i := range write[first:] {
+		if i.ID, err = config.repo.Create(*i); err != nil {
+			return err
+		}
+	}
+
+	if !hasLast {
+		return nil
+	}
+
+	return config.repo.Update(*write[0])
+}
+
+// GenericCSVParser reads CSV files with a header naming at least a "start"
+// column and either "end" or "duration". An optional "category" column is
+// mapped with ParseCategory.
+//
+// Times may be RFC 3339 or "2006-01-02 15:04[:05]" in local time. Durations
+// may be Go durations ("25m"), seconds or "HH:MM:SS".
+type GenericCSVParser struct{}
+
+func (GenericCSVParser) Parse(r io.Reader) ([]ImportRow, error) {
+	return parseCSV(r, []string{"start"}, func(rec csvRecord) (Interval, error) {
+		start, err := parseImportTime(rec.get("start"), "")
+		if err != nil {
+			return Interval{}, fmt.Errorf("start: %w", err)
+		}
+
+		var d time.Duration
+		if end := rec.get("end"); end != "" {
+			t, err := parseImportTime(end, "")
+			if err != nil {
+				return Interval{}, fmt.Errorf("end: %w", err)
+			}
+			d = t.Sub(start)
+		} else if d, err = parseImportDuration(rec.get("duration")); err != nil {
+			return Interval{}, fmt.Errorf("duration: %w", err)
+		}
+
+		return importedInterval(start, d, ParseCategory(rec.get("category"), d))
+	})
+}
+
+// TogglParser reads Toggl Track's detailed CSV export, using the
+// "Start date", "Start time", "End date", "End time" and "Duration"
+// columns. Entries are pomodoros unless their description, project or tags
+// mention a break.
+type TogglParser struct{}
+
+func (TogglParser) Parse(r io.Reader) ([]ImportRow, error) {
+	required := []string{"start date", "start time"}
+
+	return parseCSV(r, required, func(rec csvRecord) (Interval, error) {
+		start, err := parseImportTime(rec.get("start date"), rec.get("start time"))
+		if err != nil {
+			return Interval{}, fmt.Errorf("start: %w", err)
+		}
+
+		var d time.Duration
+		if rec.get("end date") != "" {
+			end, err := parseImportTime(rec.get("end date"), rec.get("end time"))
+			if err != nil {
+				return Interval{}, fmt.Errorf("end: %w", err)
+			}
+			d = end.Sub(start)
+		} else if d, err = parseImportDuration(rec.get("duration")); err != nil {
+			return Interval{}, fmt.Errorf("duration: %w", err)
+		}
+
+		label := strings.Join([]string{
+			rec.get("description"), rec.get("project"), rec.get("tags"),
+		}, " ")
+
+		return importedInterval(start, d, ParseCategory(label, d))
+	})
+}
+
+// ParseCategory maps a free-form label onto a category. Labels mentioning
+// a break are long breaks if they say "long" or last 15 minutes or more,
+// and short breaks otherwise. Everything else is a pomodoro.
+func ParseCategory(label string, d time.Duration) string {
+	label = strings.ToLower(label)
+
+	switch {
+	case !strings.Contains(label, "break"):
+		return CategoryPomodoro
+	case strings.Contains(label, "long") || d >= 15*time.Minute:
+		return CategoryLongBreak
+	default:
+		return CategoryShortBreak
+	}
+}
+
+func importedInterval(start time.Time, d time.Duration, category string) (Interval, error) {
+	if d <= 0 {
+		return Interval{}, fmt.Errorf("non-positive duration %s", d)
+	}
+
+	return Interval{
+		StartTime:       start,
+		PlannedDuration: d,
+		ActualDuration:  d,
+		Category:        category,
+		State:           StateDone,
+	}, nil
+}
+
+type csvRecord struct {
+	columns map[string]int
+	fields  []string
+}
+
+// get returns the trimmed field of the named column, or "" if the file
+// has no such column.
+func (rec csvRecord) get(name string) string {
+	k, ok := rec.columns[name]
+	if !ok || k >= len(rec.fields) {
+		return ""
+	}
+
+	return strings.TrimSpace(rec.fields[k])
+}
+
+// parseCSV reads a CSV file with a header line and maps each record with
+// fn. Column names are matched case-insensitively.
+func parseCSV(
+	r io.Reader,
+	required []string,
+	fn func(csvRecord) (Interval, error),
+) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for k, name := range header {
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = k
+	}
+
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrMissingColumn, name)
+		}
+	}
+
+	var rows []ImportRow
+
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+
+		var pe *csv.ParseError
+		switch {
+		case errors.As(err, &pe):
+			rows = append(rows, ImportRow{Line: pe.StartLine, Err: err})
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		row := ImportRow{}
+		row.Line, _ = cr.FieldPos(0)
+		row.Interval, row.Err = fn(csvRecord{columns, fields})
+
+		rows = append(rows, row)
+	}
+}
+
+var importTimeLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+}
+
+// parseImportTime parses an RFC 3339 timestamp, or a local date and time
+// given either together or in separate fields.
+func parseImportTime(date, clock string) (time.Time, error) {
+	s := strings.TrimSpace(date + " " + clock)
+	if s == "" {
+		return time.Time{}, errors.New("empty time")
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range importTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// parseImportDuration parses "HH:MM:SS", a Go duration or seconds.
+func parseImportDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty duration")
+	}
+
+	if parts := strings.Split(s, ":"); len(parts) == 3 {
+		var total time.Duration
+		for k, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+			n, err := strconv.Atoi(parts[k])
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			total += time.Duration(n) * unit
+		}
+
+		return total, nil
+	}
+
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return secondsToDuration(secs), nil
+	}
+
+	return time.ParseDuration(s)
+}
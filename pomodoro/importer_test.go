@@ -0,0 +1,306 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func TestParsers(t *testing.T) {
+	day := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name    string
+		format  string
+		input   string
+		exp     []string
+		expErrs []int
+	}{
+		{
+			name:   "Generic",
+			format: "generic",
+			input: `start,end,category
+2025-03-10 09:00,2025-03-10 09:25,work
+2025-03-10 09:25,2025-03-10 09:30,Short Break
+2025-03-10 11:00,2025-03-10 11:20,break
+2025-03-10 12:00,2025-03-10 11:00,work
+`,
+			exp: []string{
+				pomodoro.CategoryPomodoro,
+				pomodoro.CategoryShortBreak,
+				pomodoro.CategoryLongBreak,
+			},
+			expErrs: []int{5},
+		},
+		{
+			name:   "GenericDuration",
+			format: "generic",
+			input: "\ufeffStart,Duration\n" +
+				"2025-03-10T09:00:00,25m\n" +
+				"2025-03-10 10:00,00:25:00\n" +
+				"2025-03-10 11:00,1500\n" +
+				"2025-03-10 12:00,\n",
+			exp: []string{
+				pomodoro.CategoryPomodoro,
+				pomodoro.CategoryPomodoro,
+				pomodoro.CategoryPomodoro,
+			},
+			expErrs: []int{5},
+		},
+		{
+			name:   "Toggl",
+			format: "toggl",
+			input: `User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()
+Ana,ana@example.com,,Docs,,Write README,No,2025-03-10,09:00:00,2025-03-10,09:25:00,00:25:00,,
+Ana,ana@example.com,,,,Coffee,No,2025-03-10,09:25:00,2025-03-10,09:30:00,00:05:00,break,
+Ana,ana@example.com,,Docs,,Review,No,2025-03-10,nine,2025-03-10,09:55:00,00:25:00,,
+`,
+			exp: []string{
+				pomodoro.CategoryPomodoro,
+				pomodoro.CategoryShortBreak,
+			},
+			expErrs: []int{4},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := pomodoro.NewParser(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := p.Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			var errLines []int
+			for _, r := range rows {
+				if r.Err != nil {
+					errLines = append(errLines, r.Line)
+					continue
+				}
+
+				if r.Interval.State != pomodoro.StateDone {
+					t.Errorf("Line %d: expected state %d, got %d instead.\n",
+						r.Line, pomodoro.StateDone, r.Interval.State)
+				}
+
+				if r.Interval.StartTime.Before(day) || r.Interval.ActualDuration <= 0 {
+					t.Errorf("Line %d: unexpected interval %+v", r.Line, r.Interval)
+				}
+
+				got = append(got, r.Interval.Category)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.exp, ",") {
+				t.Errorf("Expected categories %v, got %v instead.\n", tt.exp, got)
+			}
+
+			if len(errLines) != len(tt.expErrs) || (len(errLines) > 0 && errLines[0] != tt.expErrs[0]) {
+				t.Errorf("Expected errors on lines %v, got %v instead.\n", tt.expErrs, errLines)
+			}
+		})
+	}
+}
+
+func TestParserMissingColumn(t *testing.T) {
+	p, err := pomodoro.NewParser("toggl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.Parse(strings.NewReader("start,end\n"))
+	if !errors.Is(err, pomodoro.ErrMissingColumn) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrMissingColumn, err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	// A pomodoro already recorded, and one paused interval to resume.
+	start := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.Local)
+	if _, err := repo.Create(pomodoro.Interval{
+		StartTime: start, Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone,
+		PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	paused, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paused.State = pomodoro.StatePaused
+	paused.StartTime = time.Now()
+	paused.ActualDuration = 2 * time.Minute
+	if err := repo.Update(paused); err != nil {
+		t.Fatal(err)
+	}
+
+	input := `start,duration,category
+2025-03-10 09:10,25m,Pomodoro
+2025-03-10 10:00,25m,Pomodoro
+2025-03-10 10:25,5m,ShortBreak
+2025-03-10 10:27,5m,ShortBreak
+bogus,5m,ShortBreak
+`
+
+	parse := func() []pomodoro.ImportRow {
+		p, _ := pomodoro.NewParser("generic")
+		rows, err := p.Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+
+	sum, err := pomodoro.Import(config, parse(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := pomodoro.ImportSummary{Imported: 2, Duplicates: 2, Errors: 1}
+	if sum != exp {
+		t.Errorf("Expected dry run %+v, got %+v instead.\n", exp, sum)
+	}
+
+	ds, err := pomodoro.DailySummary(start, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds[0] != 25*time.Minute {
+		t.Errorf("Expected dry run to leave %q of pomodoros, got %q.\n", 25*time.Minute, ds[0])
+	}
+
+	rows := parse()
+	if sum, err = pomodoro.Import(config, rows, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if sum != exp {
+		t.Errorf("Expected %+v, got %+v instead.\n", exp, sum)
+	}
+
+	if !rows[0].Duplicate || rows[1].Duplicate || rows[1].Interval.ID == 0 {
+		t.Errorf("Unexpected rows after import: %+v", rows[:2])
+	}
+
+	ds, err = pomodoro.DailySummary(start, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ds[0] != 50*time.Minute || ds[1] != 5*time.Minute {
+		t.Errorf("Expected summary [50m 5m], got %v instead.\n", ds)
+	}
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i.State != pomodoro.StatePaused || i.Category != paused.Category {
+		t.Errorf("Expected paused %s to remain current, got %+v instead.\n",
+			paused.Category, i)
+	}
+
+	if i.ActualDuration != paused.ActualDuration {
+		t.Errorf("Expected the paused interval to keep %q, got %q instead.\n",
+			paused.ActualDuration, i.ActualDuration)
+	}
+
+	d, err := repo.CategorySummary(paused.StartTime, paused.Category)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d != paused.ActualDuration {
+		t.Errorf("Expected %q of %s counted once, got %q instead.\n",
+			paused.ActualDuration, paused.Category, d)
+	}
+
+	all, err := repo.Range(time.Time{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range all {
+		if i.State == pomodoro.StateCancelled {
+			t.Errorf("Expected no cancelled copy of the paused interval, got %+v.\n", i)
+		}
+	}
+}
+
+func TestImportAfterDone(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	// Today ended with a short break, so a pomodoro comes next.
+	now := time.Now()
+	for _, i := range []pomodoro.Interval{
+		{StartTime: now.Add(-time.Hour), Category: pomodoro.CategoryPomodoro},
+		{StartTime: now.Add(-35 * time.Minute), Category: pomodoro.CategoryShortBreak},
+	} {
+		i.State = pomodoro.StateDone
+		i.PlannedDuration, i.ActualDuration = 5*time.Minute, 5*time.Minute
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The history from two weeks ago ends with a pomodoro.
+	old := now.AddDate(0, 0, -14).Format("2006-01-02")
+	input := "start,duration,category\n" +
+		old + " 09:00,25m,Pomodoro\n" +
+		old + " 09:25,5m,ShortBreak\n" +
+		old + " 09:30,25m,Pomodoro\n"
+
+	p, _ := pomodoro.NewParser("generic")
+	rows, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := pomodoro.Import(config, rows, false); err != nil {
+		t.Fatal(err)
+	}
+
+	category, err := pomodoro.NextCategory(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if category != pomodoro.CategoryPomodoro {
+		t.Errorf("Expected next category %q, got %q instead.\n", pomodoro.CategoryPomodoro, category)
+	}
+
+	last, err := repo.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !last.StartTime.Equal(now.Add(-35 * time.Minute)) {
+		t.Errorf("Expected the short break to stay last, got %+v instead.\n", last)
+	}
+
+	all, err := repo.Range(time.Time{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 5 {
+		t.Errorf("Expected 5 intervals, got %d instead.\n", len(all))
+	}
+}
@@ -7,11 +7,10 @@ import (
 	"testing"
 
 	"github.com/ZeroBl21/go-ztimer/pomodoro"
-	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
 )
 
 func getRepo(t *testing.T) (pomodoro.Repository, func()) {
 	t.Helper()
 
-	return repository.NewInMemoryRepo(), func() {}
+	return openRepo(t, "memory")
 }
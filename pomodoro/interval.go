@@ -26,8 +26,9 @@ var (
 	ErrIntervalNotRunning = errors.New("Interval not running")
 	ErrIntervalCompleted  = errors.New("Interval is completed or cancelled")
 
-	ErrInvalidState = errors.New("Invalid State")
-	ErrInvalidID    = errors.New("Invalid ID")
+	ErrInvalidState    = errors.New("Invalid State")
+	ErrInvalidID       = errors.New("Invalid ID")
+	ErrInvalidDuration = errors.New("Invalid duration")
 )
 
 type Repository interface {
@@ -37,6 +38,44 @@ type Repository interface {
 	Last() (Interval, error)
 	Breaks(n int) ([]Interval, error)
 	CategorySummary(day time.Time, filter string) (time.Duration, error)
+	// Range returns the started intervals whose start time falls in
+	// [from, to), ordered by start time.
+	Range(from, to time.Time) ([]Interval, error)
+}
+
+// StateUpdater is implemented by repositories that can store an interval
+// only while its stored state still matches. tick relies on it so that a
+// pause or end written by another process is not overwritten by the next
+// second of progress. The stored planned duration is kept, so that an
+// extension is not lost either.
+type StateUpdater interface {
+	UpdateIfState(i Interval, state int) (bool, error)
+}
+
+// ChangeDetector is implemented by repositories that other processes can
+// write to. Changed reports whether they did since the previous call.
+type ChangeDetector interface {
+	Changed() (bool, error)
+}
+
+// Changed reports whether another process modified the repository since
+// the last call. Repositories that cannot be shared always report false.
+func Changed(config *IntervalConfig) (bool, error) {
+	cd, ok := config.repo.(ChangeDetector)
+	if !ok {
+		return false, nil
+	}
+
+	return cd.Changed()
+}
+
+// updateRunning stores i unless its stored state is no longer running.
+func updateRunning(r Repository, i Interval) (bool, error) {
+	if su, ok := r.(StateUpdater); ok {
+		return su.UpdateIfState(i, StateRunning)
+	}
+
+	return true, r.Update(i)
 }
 
 func nextCategory(r Repository) (string, error) {
@@ -48,7 +87,12 @@ func nextCategory(r Repository) (string, error) {
 		return "", err
 	}
 
-	if li.Category == CategoryLongBreak || li.Category == CategoryShortBreak {
+	return categoryAfter(r, li.Category)
+}
+
+// categoryAfter returns the category that follows an interval of category.
+func categoryAfter(r Repository, category string) (string, error) {
+	if category == CategoryLongBreak || category == CategoryShortBreak {
 		return CategoryPomodoro, nil
 	}
 
@@ -79,25 +123,62 @@ type Interval struct {
 	State           int
 }
 
-func newInterval(config *IntervalConfig) (Interval, error) {
-	i := Interval{}
+// EndTime returns the start time plus the time actually counted.
+func (i Interval) EndTime() time.Time {
+	return i.StartTime.Add(i.ActualDuration)
+}
 
+// Remaining returns the time left before the interval expires.
+func (i Interval) Remaining() time.Duration {
+	if i.ActualDuration >= i.PlannedDuration {
+		return 0
+	}
+
+	return i.PlannedDuration - i.ActualDuration
+}
+
+// Overlaps reports whether i and o cover a common stretch of time. Two
+// intervals starting at the same instant always overlap.
+func (i Interval) Overlaps(o Interval) bool {
+	if i.StartTime.Equal(o.StartTime) {
+		return true
+	}
+
+	return i.StartTime.Before(o.EndTime()) && o.StartTime.Before(i.EndTime())
+}
+
+func newInterval(config *IntervalConfig) (Interval, error) {
 	category, err := nextCategory(config.repo)
 	if err != nil {
-		return i, err
+		return Interval{}, err
 	}
 
-	i.Category = category
+	return createInterval(config, category)
+}
 
-	switch category {
-	case CategoryPomodoro:
-		i.PlannedDuration = config.PomodoroDuration
-	case CategoryShortBreak:
-		i.PlannedDuration = config.ShortBreakDuration
-	case CategoryLongBreak:
-		i.PlannedDuration = config.LongBreakDuration
+// skipNext creates the interval after the one that would come next, so
+// that skipping while nothing is in progress stores no cancelled interval.
+func skipNext(config *IntervalConfig) (Interval, error) {
+	skipped, err := nextCategory(config.repo)
+	if err != nil {
+		return Interval{}, err
+	}
+
+	category, err := categoryAfter(config.repo, skipped)
+	if err != nil {
+		return Interval{}, err
+	}
+
+	return createInterval(config, category)
+}
+
+func createInterval(config *IntervalConfig, category string) (Interval, error) {
+	i := Interval{
+		Category:        category,
+		PlannedDuration: config.Duration(category),
 	}
 
+	var err error
 	if i.ID, err = config.repo.Create(i); err != nil {
 		return i, err
 	}
@@ -105,6 +186,17 @@ func newInterval(config *IntervalConfig) (Interval, error) {
 	return i, nil
 }
 
+// NextCategory returns the category of the interval that follows the
+// most recent one.
+func NextCategory(config *IntervalConfig) (string, error) {
+	return nextCategory(config.repo)
+}
+
+// Current returns the most recent interval without creating a new one.
+func Current(config *IntervalConfig) (Interval, error) {
+	return config.repo.Last()
+}
+
 func GetInterval(config *IntervalConfig) (Interval, error) {
 	i, err := config.repo.Last()
 	if err != nil && err != ErrNoInterval {
@@ -168,6 +260,34 @@ func (i Interval) End(config *IntervalConfig) error {
 	return config.repo.Update(i)
 }
 
+// Skip cancels an interval that has not finished so that GetInterval moves
+// on to the next one. A process ticking the interval stops on its next tick.
+func (i Interval) Skip(config *IntervalConfig) error {
+	if i.State == StateDone || i.State == StateCancelled {
+		return fmt.Errorf("%w: Cannot skip", ErrIntervalCompleted)
+	}
+
+	i.State = StateCancelled
+
+	return config.repo.Update(i)
+}
+
+// Extend adds d to the planned duration of an interval that has not
+// finished. A process ticking the interval picks it up on its next tick.
+func (i Interval) Extend(config *IntervalConfig, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidDuration, d)
+	}
+
+	if i.State == StateDone || i.State == StateCancelled {
+		return fmt.Errorf("%w: Cannot extend", ErrIntervalCompleted)
+	}
+
+	i.PlannedDuration += d
+
+	return config.repo.Update(i)
+}
+
 type Callback func(Interval)
 
 func tick(
@@ -184,7 +304,8 @@ func tick(
 		return err
 	}
 
-	expire := time.After(i.PlannedDuration - i.ActualDuration)
+	planned := i.PlannedDuration
+	expire := time.After(planned - i.ActualDuration)
 
 	start(i)
 
@@ -196,7 +317,7 @@ func tick(
 				return err
 			}
 
-			if i.State == StatePaused {
+			if i.State == StatePaused || i.State == StateCancelled {
 				return nil
 			}
 
@@ -213,10 +334,22 @@ func tick(
 
 			i.ActualDuration += time.Second
 
-			if err := config.repo.Update(i); err != nil {
+			// Extended since the timer was set.
+			if i.PlannedDuration != planned {
+				planned = i.PlannedDuration
+				expire = time.After(planned - i.ActualDuration)
+			}
+
+			ok, err := updateRunning(config.repo, i)
+			if err != nil {
 				return err
 			}
 
+			// Paused or ended elsewhere, picked up on the next tick.
+			if !ok {
+				continue
+			}
+
 			periodic(i)
 
 		case <-expire:
@@ -224,11 +357,25 @@ func tick(
 			if err != nil {
 				return err
 			}
+
+			if i.State != StateRunning {
+				continue
+			}
+
 			i.State = StateDone
 
+			ok, err := updateRunning(config.repo, i)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				continue
+			}
+
 			end(i)
 
-			return config.repo.Update(i)
+			return nil
 
 		case <-ctx.Done():
 			i, err := config.repo.ByID(id)
@@ -237,7 +384,9 @@ func tick(
 			}
 			i.State = StateCancelled
 
-			return config.repo.Update(i)
+			_, err = updateRunning(config.repo, i)
+
+			return err
 		}
 	}
 }
@@ -250,6 +399,20 @@ type IntervalConfig struct {
 	LongBreakDuration  time.Duration
 }
 
+// Duration returns the planned duration of the intervals of category.
+func (c *IntervalConfig) Duration(category string) time.Duration {
+	switch category {
+	case CategoryPomodoro:
+		return c.PomodoroDuration
+	case CategoryShortBreak:
+		return c.ShortBreakDuration
+	case CategoryLongBreak:
+		return c.LongBreakDuration
+	}
+
+	return 0
+}
+
 func NewConfig(
 	repo Repository,
 	pomodoro, shortBreak, longBreak time.Duration,
@@ -315,3 +315,92 @@ func TestStart(t *testing.T) {
 		cancel()
 	}
 }
+
+func TestSkip(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.Skip(config); err != nil {
+		t.Fatal(err)
+	}
+
+	skipped, err := repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if skipped.State != pomodoro.StateCancelled {
+		t.Errorf("Expected state %d, got %d instead.\n",
+			pomodoro.StateCancelled, skipped.State)
+	}
+
+	err = skipped.Skip(config)
+	if !errors.Is(err, pomodoro.ErrIntervalCompleted) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrIntervalCompleted, err)
+	}
+
+	next, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.ID == i.ID || next.Category != pomodoro.CategoryShortBreak {
+		t.Errorf("Expected a new %s after skipping, got %+v instead.\n",
+			pomodoro.CategoryShortBreak, next)
+	}
+
+	if d := next.Remaining(); d != next.PlannedDuration {
+		t.Errorf("Expected %q remaining, got %q instead.\n", next.PlannedDuration, d)
+	}
+}
+
+func TestExtend(t *testing.T) {
+	const duration = 2 * time.Second
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.Extend(config, 0); !errors.Is(err, pomodoro.ErrInvalidDuration) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrInvalidDuration, err)
+	}
+
+	// Extend while ticking, so the expiry has to be moved.
+	start := func(i pomodoro.Interval) {
+		if err := i.Extend(config, time.Second); err != nil {
+			t.Error(err)
+		}
+	}
+	noop := func(pomodoro.Interval) {}
+
+	if err := i.Start(context.Background(), config, start, noop, noop); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := duration + time.Second
+	if i.State != pomodoro.StateDone || i.ActualDuration != exp || i.PlannedDuration != exp {
+		t.Errorf("Expected done after %q, got %+v instead.\n", exp, i)
+	}
+
+	if err := i.Extend(config, time.Second); !errors.Is(err, pomodoro.ErrIntervalCompleted) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrIntervalCompleted, err)
+	}
+}
@@ -0,0 +1,83 @@
+package pomodoro
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EventMilestone is published by a Runner when an interval reaches one of
+// its milestones.
+const EventMilestone = "milestone"
+
+var ErrInvalidMilestone = errors.New("Invalid milestone")
+
+// Milestone is a point before the end of an interval worth announcing,
+// such as "1 minute left" or "halfway". It is set either by the time left
+// with Before or by the share of the planned duration elapsed with
+// Progress.
+type Milestone struct {
+	// Category restricts the milestone to one category, or applies it to
+	// every interval when empty.
+	Category string        `mapstructure:"category" json:"category,omitempty"`
+	Before   time.Duration `mapstructure:"before" json:"before,omitempty"`
+	Progress float64       `mapstructure:"progress" json:"progress,omitempty"`
+	Message  string        `mapstructure:"message" json:"message"`
+	// Sound is the cue played by front ends, none when empty.
+	Sound string `mapstructure:"sound" json:"sound,omitempty"`
+}
+
+// DefaultMilestones warn one minute before the end of a pomodoro.
+var DefaultMilestones = []Milestone{
+	{Category: CategoryPomodoro, Before: time.Minute, Message: "1 minute left", Sound: "warning"},
+}
+
+// Validate reports a milestone that would never be reached.
+func (m Milestone) Validate() error {
+	switch m.Category {
+	case "", CategoryPomodoro, CategoryShortBreak, CategoryLongBreak:
+	default:
+		return fmt.Errorf("%w: unknown category %q", ErrInvalidMilestone, m.Category)
+	}
+
+	switch {
+	case m.Before < 0:
+		return fmt.Errorf("%w: negative before %s", ErrInvalidMilestone, m.Before)
+	case m.Progress < 0 || m.Progress >= 1:
+		return fmt.Errorf("%w: progress %v, expected 0 to 1", ErrInvalidMilestone, m.Progress)
+	case (m.Before == 0) == (m.Progress == 0):
+		return fmt.Errorf("%w: %q needs either before or progress", ErrInvalidMilestone, m.Message)
+	case m.Message == "":
+		return fmt.Errorf("%w: missing message", ErrInvalidMilestone)
+	}
+
+	return nil
+}
+
+// at returns how long i has to run before reaching m, or false when m
+// does not apply to it.
+func (m Milestone) at(i Interval) (time.Duration, bool) {
+	if m.Category != "" && m.Category != i.Category {
+		return 0, false
+	}
+
+	at := i.PlannedDuration - m.Before
+	if m.Progress > 0 {
+		at = time.Duration(float64(i.PlannedDuration) * m.Progress)
+	}
+
+	return at, at > 0 && at < i.PlannedDuration
+}
+
+// reached returns the milestones of ms that i crossed since it had run for
+// from.
+func reached(ms []Milestone, from time.Duration, i Interval) []Milestone {
+	var crossed []Milestone
+	for _, m := range ms {
+		if at, ok := m.at(i); ok && from < at && at <= i.ActualDuration {
+			crossed = append(crossed, m)
+		}
+	}
+
+	return crossed
+}
@@ -0,0 +1,40 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func TestMilestoneValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		milestone pomodoro.Milestone
+		expErr    error
+	}{
+		{name: "Before", milestone: pomodoro.Milestone{Before: time.Minute, Message: "1 minute left"}},
+		{name: "Progress", milestone: pomodoro.Milestone{Progress: 0.5, Message: "Halfway"}},
+		{name: "Neither", milestone: pomodoro.Milestone{Message: "Never"},
+			expErr: pomodoro.ErrInvalidMilestone},
+		{name: "Both", milestone: pomodoro.Milestone{Before: time.Minute, Progress: 0.5, Message: "Twice"},
+			expErr: pomodoro.ErrInvalidMilestone},
+		{name: "Finished", milestone: pomodoro.Milestone{Progress: 1, Message: "End"},
+			expErr: pomodoro.ErrInvalidMilestone},
+		{name: "Category", milestone: pomodoro.Milestone{Category: "Lunch", Before: time.Minute, Message: "Eat"},
+			expErr: pomodoro.ErrInvalidMilestone},
+		{name: "Message", milestone: pomodoro.Milestone{Before: time.Minute},
+			expErr: pomodoro.ErrInvalidMilestone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.milestone.Validate()
+
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("Expected error %v, got %v instead.\n", tc.expErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+//go:build ndjson
+// +build ndjson
+
+package pomodoro_test
+
+import (
+	"testing"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func getRepo(t *testing.T) (pomodoro.Repository, func()) {
+	t.Helper()
+
+	return openRepo(t, "ndjson")
+}
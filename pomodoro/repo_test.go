@@ -0,0 +1,28 @@
+package pomodoro_test
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+// openRepo opens the backend scheme on a file in a temporary directory.
+// getRepo picks the backend with build tags: sqlite by default, inmemory or
+// ndjson.
+func openRepo(t *testing.T, scheme string) (pomodoro.Repository, func()) {
+	t.Helper()
+
+	repo, err := repository.Open(scheme + ":" + filepath.Join(t.TempDir(), "pomo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repo, func() {
+		if c, ok := repo.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// likeMatch reports whether s matches an SQL LIKE pattern where "%" matches
+// any run of characters. Like SQLite, matching ignores ASCII case.
+func likeMatch(pattern, s string) bool {
+	pattern, s = strings.ToLower(pattern), strings.ToLower(s)
+
+	parts := strings.Split(pattern, "%")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		k := strings.Index(s, p)
+		if k < 0 {
+			return false
+		}
+		s = s[k+len(p):]
+	}
+
+	return strings.HasSuffix(s, last)
+}
+
+// sameLocalDay reports whether a and b fall on the same local calendar day.
+func sameLocalDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+
+	return ay == by && am == bm && ad == bd
+}
+
+// inRange returns the started intervals with a start time in [from, to),
+// ordered by start time.
+func inRange(intervals []pomodoro.Interval, from, to time.Time) []pomodoro.Interval {
+	data := []pomodoro.Interval{}
+
+	for _, i := range intervals {
+		if i.StartTime.IsZero() || i.StartTime.Before(from) || !i.StartTime.Before(to) {
+			continue
+		}
+
+		data = append(data, i)
+	}
+
+	sort.SliceStable(data, func(a, b int) bool {
+		return data[a].StartTime.Before(data[b].StartTime)
+	})
+
+	return data
+}
+
+// overlapsGap reports whether g covers time also covered by one of gaps.
+func overlapsGap(g pomodoro.Gap, gaps []pomodoro.Gap) bool {
+	for _, o := range gaps {
+		if g.StartTime.Before(o.EndTime) && o.StartTime.Before(g.EndTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gapsInRange returns the gaps with a start time in [from, to), ordered by
+// start time.
+func gapsInRange(gaps []pomodoro.Gap, from, to time.Time) []pomodoro.Gap {
+	data := []pomodoro.Gap{}
+
+	for _, g := range gaps {
+		if g.StartTime.Before(from) || !g.StartTime.Before(to) {
+			continue
+		}
+
+		data = append(data, g)
+	}
+
+	sort.SliceStable(data, func(a, b int) bool {
+		return data[a].StartTime.Before(data[b].StartTime)
+	})
+
+	return data
+}
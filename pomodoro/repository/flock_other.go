@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package repository
+
+import "os"
+
+// Platforms without advisory locks only get in-process safety.
+func lockFile(f *os.File, exclusive bool) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
@@ -1,20 +1,24 @@
-//go:build inmemory
-// +build inmemory
-
 package repository
 
 import (
 	"fmt"
-	"strings"
+	"net/url"
 	"sync"
 	"time"
 
 	"github.com/ZeroBl21/go-ztimer/pomodoro"
 )
 
+func init() {
+	Register("memory", func(*url.URL) (pomodoro.Repository, error) {
+		return NewInMemoryRepo(), nil
+	})
+}
+
 type inMemoryRepo struct {
 	sync.RWMutex
 	intervals []pomodoro.Interval
+	gaps      []pomodoro.Gap
 }
 
 func NewInMemoryRepo() *inMemoryRepo {
@@ -38,7 +42,7 @@ func (r *inMemoryRepo) Update(i pomodoro.Interval) error {
 	r.Lock()
 	defer r.Unlock()
 
-	if i.ID == 0 {
+	if i.ID <= 0 || i.ID > int64(len(r.intervals)) {
 		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
 	}
 	r.intervals[i.ID-1] = i
@@ -46,13 +50,30 @@ func (r *inMemoryRepo) Update(i pomodoro.Interval) error {
 	return nil
 }
 
+func (r *inMemoryRepo) UpdateIfState(i pomodoro.Interval, state int) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if i.ID <= 0 || i.ID > int64(len(r.intervals)) {
+		return false, fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+	}
+
+	if r.intervals[i.ID-1].State != state {
+		return false, nil
+	}
+	i.PlannedDuration = r.intervals[i.ID-1].PlannedDuration
+	r.intervals[i.ID-1] = i
+
+	return true, nil
+}
+
 func (r *inMemoryRepo) ByID(id int64) (pomodoro.Interval, error) {
 	r.RLock()
 	defer r.RUnlock()
 
 	i := pomodoro.Interval{}
 
-	if id <= 0 {
+	if id <= 0 || id > int64(len(r.intervals)) {
 		return i, fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
 	}
 
@@ -74,13 +95,13 @@ func (r *inMemoryRepo) Last() (pomodoro.Interval, error) {
 }
 
 func (r *inMemoryRepo) Breaks(n int) ([]pomodoro.Interval, error) {
-	r.Lock()
-	defer r.Unlock()
+	r.RLock()
+	defer r.RUnlock()
 
 	data := []pomodoro.Interval{}
 
 	for k := len(r.intervals) - 1; k >= 0; k-- {
-		if r.intervals[k].Category == pomodoro.CategoryPomodoro {
+		if !likeMatch("%Break", r.intervals[k].Category) {
 			continue
 		}
 
@@ -101,15 +122,39 @@ func (r *inMemoryRepo) CategorySummary(day time.Time, filter string) (time.Durat
 
 	var d time.Duration
 
-	filter = strings.Trim(filter, "%")
-
 	for _, i := range r.intervals {
-		if i.StartTime.Year() == day.Year() &&
-			i.StartTime.YearDay() == day.YearDay() &&
-			strings.Contains(i.Category, filter) {
+		if sameLocalDay(i.StartTime, day) && likeMatch(filter, i.Category) {
 			d += i.ActualDuration
 		}
 	}
 
 	return d, nil
 }
+
+func (r *inMemoryRepo) Range(from, to time.Time) ([]pomodoro.Interval, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return inRange(r.intervals, from, to), nil
+}
+
+func (r *inMemoryRepo) CreateGap(g pomodoro.Gap) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if overlapsGap(g, r.gaps) {
+		return 0, nil
+	}
+
+	g.ID = int64(len(r.gaps)) + 1
+	r.gaps = append(r.gaps, g)
+
+	return g.ID, nil
+}
+
+func (r *inMemoryRepo) Gaps(from, to time.Time) ([]pomodoro.Gap, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return gapsInRange(r.gaps, from, to), nil
+}
@@ -0,0 +1,577 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// compactThreshold is the minimum number of log lines before the file is
+// rewritten. Compaction only happens once the log holds more than twice as
+// many lines as there are intervals.
+const compactThreshold = 512
+
+func init() {
+	open := func(dsn *url.URL) (pomodoro.Repository, error) {
+		path := dsnPath(dsn)
+		if path == "" {
+			return nil, errors.New("json: missing file path")
+		}
+
+		return NewFileRepo(path)
+	}
+
+	Register("json", open)
+	Register("ndjson", open)
+}
+
+// fileRecord is the on-disk form of an interval, one JSON object per line.
+// Later lines for the same id replace earlier ones. Lines of gaps have
+// their own kind and ids, and are read into the same struct.
+type fileRecord struct {
+	Kind            string    `json:"kind,omitempty"`
+	ID              int64     `json:"id"`
+	StartTime       time.Time `json:"start_time"`
+	PlannedDuration string    `json:"planned_duration"`
+	ActualDuration  string    `json:"actual_duration"`
+	Category        string    `json:"category"`
+	State           int       `json:"state"`
+	EndTime         time.Time `json:"end_time,omitzero"`
+}
+
+const kindGap = "gap"
+
+// gapRecord is the on-disk form of a gap.
+type gapRecord struct {
+	Kind      string    `json:"kind"`
+	ID        int64     `json:"id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+func newGapRecord(g pomodoro.Gap) gapRecord {
+	return gapRecord{Kind: kindGap, ID: g.ID, StartTime: g.StartTime, EndTime: g.EndTime}
+}
+
+func newFileRecord(i pomodoro.Interval) fileRecord {
+	return fileRecord{
+		ID:              i.ID,
+		StartTime:       i.StartTime,
+		PlannedDuration: i.PlannedDuration.String(),
+		ActualDuration:  i.ActualDuration.String(),
+		Category:        i.Category,
+		State:           i.State,
+	}
+}
+
+func (rec fileRecord) interval() (pomodoro.Interval, error) {
+	i := pomodoro.Interval{
+		ID:        rec.ID,
+		StartTime: rec.StartTime,
+		Category:  rec.Category,
+		State:     rec.State,
+	}
+
+	var err error
+	if i.PlannedDuration, err = time.ParseDuration(rec.PlannedDuration); err != nil {
+		return i, err
+	}
+	if i.ActualDuration, err = time.ParseDuration(rec.ActualDuration); err != nil {
+		return i, err
+	}
+
+	return i, nil
+}
+
+// fileRepo stores intervals in an append-only NDJSON log. Every operation
+// takes an advisory lock on a sibling ".lock" file and catches up with lines
+// appended by other processes before touching the in-memory copy.
+type fileRepo struct {
+	sync.Mutex
+
+	path string
+	lock *os.File
+
+	intervals []pomodoro.Interval
+	gaps      []pomodoro.Gap
+	info      os.FileInfo
+	offset    int64
+	lines     int
+
+	// changed is set when load picks up lines written by someone else.
+	changed bool
+}
+
+func NewFileRepo(path string) (*fileRepo, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &fileRepo{
+		path:      path,
+		lock:      lock,
+		intervals: []pomodoro.Interval{},
+	}
+
+	if err := r.read(func() error { return nil }); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	r.changed = false
+
+	return r, nil
+}
+
+// read runs fn with a shared file lock after syncing with the log.
+func (r *fileRepo) read(fn func() error) error {
+	if err := lockFile(r.lock, false); err != nil {
+		return err
+	}
+	defer unlockFile(r.lock)
+
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// write runs fn with an exclusive file lock after syncing with the log.
+func (r *fileRepo) write(fn func() error) error {
+	if err := lockFile(r.lock, true); err != nil {
+		return err
+	}
+	defer unlockFile(r.lock)
+
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	if r.lines >= compactThreshold && r.lines > 2*(len(r.intervals)+len(r.gaps)) {
+		return r.compact()
+	}
+
+	return nil
+}
+
+// load brings the in-memory copy up to date with the log. It only reads the
+// lines appended since the last call, unless the file was replaced.
+func (r *fileRepo) load() error {
+	info, err := os.Stat(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		r.reset(nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if r.info == nil || !os.SameFile(r.info, info) || info.Size() < r.offset {
+		r.reset(info)
+	}
+
+	if info.Size() == r.offset {
+		return nil
+	}
+
+	f, err := os.Open(r.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(r.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	br := bufio.NewReader(f)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// An unterminated line is a write in progress or a torn write;
+			// leave it for the next load or the next compaction.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := r.apply(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", r.path, r.lines+1, err)
+		}
+
+		r.offset += int64(len(line))
+		r.lines++
+		r.changed = true
+	}
+}
+
+func (r *fileRepo) reset(info os.FileInfo) {
+	r.intervals = []pomodoro.Interval{}
+	r.gaps = nil
+	r.info = info
+	r.offset = 0
+	r.lines = 0
+}
+
+func (r *fileRepo) apply(line []byte) error {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil
+	}
+
+	var rec fileRecord
+	if err := json.Unmarshal(line, &rec); err != nil {
+		return err
+	}
+
+	switch rec.Kind {
+	case "":
+	case kindGap:
+		if rec.ID != int64(len(r.gaps))+1 {
+			return fmt.Errorf("%w: gap %d is out of sequence", pomodoro.ErrInvalidID, rec.ID)
+		}
+
+		r.gaps = append(r.gaps, pomodoro.Gap{ID: rec.ID, StartTime: rec.StartTime, EndTime: rec.EndTime})
+		return nil
+	default:
+		return fmt.Errorf("unknown record kind %q", rec.Kind)
+	}
+
+	i, err := rec.interval()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case i.ID <= 0:
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+	case i.ID <= int64(len(r.intervals)):
+		r.intervals[i.ID-1] = i
+	case i.ID == int64(len(r.intervals))+1:
+		r.intervals = append(r.intervals, i)
+	default:
+		return fmt.Errorf("%w: %d is out of sequence", pomodoro.ErrInvalidID, i.ID)
+	}
+
+	return nil
+}
+
+// appendRecord writes rec to the end of the log and applies it locally.
+// The caller must hold the exclusive file lock.
+func (r *fileRepo) appendRecord(rec any) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	// Drop the tail of a torn write so our line starts on its own.
+	if info, err := os.Stat(r.path); err == nil && info.Size() > r.offset {
+		if err := os.Truncate(r.path, r.offset); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// Nobody else can write while we hold the lock, so the log now ends
+	// with our line.
+	return r.loadOwn()
+}
+
+// loadOwn loads lines this repository wrote itself, without flagging them
+// as a change.
+func (r *fileRepo) loadOwn() error {
+	changed := r.changed
+	err := r.load()
+	r.changed = changed
+
+	return err
+}
+
+// compact rewrites the log with a single line per interval and gap, and
+// atomically replaces the old file. The caller must hold the exclusive file lock.
+func (r *fileRepo) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, i := range r.intervals {
+		if err := enc.Encode(newFileRecord(i)); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	for _, g := range r.gaps {
+		if err := enc.Encode(newGapRecord(g)); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return err
+	}
+
+	r.reset(nil)
+
+	return r.loadOwn()
+}
+
+func (r *fileRepo) Create(i pomodoro.Interval) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	err := r.write(func() error {
+		i.ID = int64(len(r.intervals)) + 1
+		return r.appendRecord(newFileRecord(i))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return i.ID, nil
+}
+
+func (r *fileRepo) Update(i pomodoro.Interval) error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.write(func() error {
+		if i.ID <= 0 || i.ID > int64(len(r.intervals)) {
+			return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+		}
+
+		return r.appendRecord(newFileRecord(i))
+	})
+}
+
+// UpdateIfState updates i only if its stored state is still state. The
+// planned duration is left as stored.
+func (r *fileRepo) UpdateIfState(i pomodoro.Interval, state int) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	updated := false
+
+	err := r.write(func() error {
+		if i.ID <= 0 || i.ID > int64(len(r.intervals)) {
+			return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+		}
+
+		if r.intervals[i.ID-1].State != state {
+			return nil
+		}
+		i.PlannedDuration = r.intervals[i.ID-1].PlannedDuration
+
+		updated = true
+		return r.appendRecord(newFileRecord(i))
+	})
+
+	return updated, err
+}
+
+// Changed reports whether another process wrote to the log since the
+// previous call.
+func (r *fileRepo) Changed() (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if err := lockFile(r.lock, false); err != nil {
+		return false, err
+	}
+	defer unlockFile(r.lock)
+
+	if err := r.load(); err != nil {
+		return false, err
+	}
+
+	changed := r.changed
+	r.changed = false
+
+	return changed, nil
+}
+
+func (r *fileRepo) ByID(id int64) (pomodoro.Interval, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	i := pomodoro.Interval{}
+
+	err := r.read(func() error {
+		if id <= 0 || id > int64(len(r.intervals)) {
+			return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+		}
+
+		i = r.intervals[id-1]
+		return nil
+	})
+
+	return i, err
+}
+
+func (r *fileRepo) Last() (pomodoro.Interval, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	i := pomodoro.Interval{}
+
+	err := r.read(func() error {
+		if len(r.intervals) == 0 {
+			return pomodoro.ErrNoInterval
+		}
+
+		i = r.intervals[len(r.intervals)-1]
+		return nil
+	})
+
+	return i, err
+}
+
+func (r *fileRepo) Breaks(n int) ([]pomodoro.Interval, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	data := []pomodoro.Interval{}
+
+	err := r.read(func() error {
+		for k := len(r.intervals) - 1; k >= 0 && len(data) < n; k-- {
+			if likeMatch("%Break", r.intervals[k].Category) {
+				data = append(data, r.intervals[k])
+			}
+		}
+
+		return nil
+	})
+
+	return data, err
+}
+
+func (r *fileRepo) CategorySummary(day time.Time, filter string) (time.Duration, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var d time.Duration
+
+	err := r.read(func() error {
+		for _, i := range r.intervals {
+			if sameLocalDay(i.StartTime, day) && likeMatch(filter, i.Category) {
+				d += i.ActualDuration
+			}
+		}
+
+		return nil
+	})
+
+	return d, err
+}
+
+func (r *fileRepo) Range(from, to time.Time) ([]pomodoro.Interval, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var data []pomodoro.Interval
+
+	err := r.read(func() error {
+		data = inRange(r.intervals, from, to)
+		return nil
+	})
+
+	return data, err
+}
+
+func (r *fileRepo) CreateGap(g pomodoro.Gap) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	g.ID = 0
+
+	err := r.write(func() error {
+		if overlapsGap(g, r.gaps) {
+			return nil
+		}
+
+		g.ID = int64(len(r.gaps)) + 1
+		return r.appendRecord(newGapRecord(g))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return g.ID, nil
+}
+
+func (r *fileRepo) Gaps(from, to time.Time) ([]pomodoro.Gap, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var data []pomodoro.Gap
+
+	err := r.read(func() error {
+		data = gapsInRange(r.gaps, from, to)
+		return nil
+	})
+
+	return data, err
+}
+
+// Close releases the lock file handle.
+func (r *fileRepo) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.lock.Close()
+}
@@ -0,0 +1,201 @@
+package repository_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+func TestFileRepoSharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.ndjson")
+
+	r1, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r1.Close()
+
+	r2, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+
+	id, err := r1.Create(pomodoro.Interval{
+		Category:        pomodoro.CategoryPomodoro,
+		PlannedDuration: 25 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := r2.ByID(id)
+	if err != nil {
+		t.Fatalf("Expected no error, got %q.\n", err)
+	}
+
+	i.State = pomodoro.StatePaused
+	if err := r2.Update(i); err != nil {
+		t.Fatal(err)
+	}
+
+	last, err := r1.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last.State != pomodoro.StatePaused {
+		t.Errorf("Expected state %d, got %d instead.\n",
+			pomodoro.StatePaused, last.State)
+	}
+}
+
+func TestFileRepoChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.ndjson")
+
+	r1, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r1.Close()
+
+	r2, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r2.Close()
+
+	if _, err := r1.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := r1.Changed(); err != nil || changed {
+		t.Errorf("Expected own write to be ignored, got %t, %v.\n", changed, err)
+	}
+
+	if _, err := r2.Create(pomodoro.Interval{Category: pomodoro.CategoryShortBreak}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reads catch up with the log but the change is still reported.
+	if _, err := r1.Last(); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := r1.Changed(); err != nil || !changed {
+		t.Errorf("Expected change from other writer, got %t, %v.\n", changed, err)
+	}
+
+	if changed, err := r1.Changed(); err != nil || changed {
+		t.Errorf("Expected change to be reported once, got %t, %v.\n", changed, err)
+	}
+}
+
+func TestFileRepoCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.ndjson")
+
+	r, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	i := pomodoro.Interval{
+		StartTime:       time.Now(),
+		Category:        pomodoro.CategoryPomodoro,
+		PlannedDuration: 25 * time.Minute,
+		State:           pomodoro.StateRunning,
+	}
+	if i.ID, err = r.Create(i); err != nil {
+		t.Fatal(err)
+	}
+
+	const ticks = 1000
+	for range ticks {
+		i.ActualDuration += time.Second
+		if err := r.Update(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := bytes.Count(data, []byte("\n")); n >= ticks {
+		t.Errorf("Expected log to be compacted, got %d lines.\n", n)
+	}
+
+	reopened, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ActualDuration != ticks*time.Second {
+		t.Errorf("Expected duration %q, got %q instead.\n",
+			ticks*time.Second, got.ActualDuration)
+	}
+
+	if !got.StartTime.Equal(i.StartTime) {
+		t.Errorf("Expected start time %q, got %q instead.\n",
+			i.StartTime, got.StartTime)
+	}
+}
+
+func TestFileRepoTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.ndjson")
+
+	log := `{"id":1,"start_time":"0001-01-01T00:00:00Z","planned_duration":"25m0s","actual_duration":"0s","category":"Pomodoro","state":0}
+{"id":1,"start_ti`
+
+	if err := os.WriteFile(path, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %q.\n", err)
+	}
+	defer r.Close()
+
+	i, err := r.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i.ID != 1 || i.PlannedDuration != 25*time.Minute {
+		t.Errorf("Expected interval 1 of 25m, got %d of %q instead.\n",
+			i.ID, i.PlannedDuration)
+	}
+
+	if _, err := r.Create(pomodoro.Interval{Category: pomodoro.CategoryShortBreak}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := repository.NewFileRepo(path)
+	if err != nil {
+		t.Fatalf("Expected no error after append, got %q.\n", err)
+	}
+	defer reopened.Close()
+
+	if i, err = reopened.Last(); err != nil {
+		t.Fatal(err)
+	}
+
+	if i.ID != 2 || i.Category != pomodoro.CategoryShortBreak {
+		t.Errorf("Expected interval 2 %q, got %d %q instead.\n",
+			pomodoro.CategoryShortBreak, i.ID, i.Category)
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+var ErrUnknownBackend = errors.New("Unknown storage backend")
+
+// Opener creates a repository from a parsed storage DSN such as
+// "sqlite:pomo.db" or "memory:".
+type Opener func(dsn *url.URL) (pomodoro.Repository, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]Opener{}
+)
+
+// Register makes a storage backend available under the given scheme. It
+// panics if the scheme is registered twice.
+func Register(scheme string, open Opener) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if open == nil {
+		panic("repository: Register opener is nil")
+	}
+	if _, ok := backends[scheme]; ok {
+		panic("repository: Register called twice for backend " + scheme)
+	}
+
+	backends[scheme] = open
+}
+
+// Backends returns the sorted list of registered schemes.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Open resolves the DSN scheme to a registered backend and opens it.
+func Open(dsn string) (pomodoro.Repository, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := u.Scheme
+	if scheme == "" {
+		// A bare backend name, e.g. "memory".
+		scheme, u = u.Path, &url.URL{Scheme: u.Path}
+	}
+
+	backendsMu.RLock()
+	open, ok := backends[strings.ToLower(scheme)]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (available: %s)",
+			ErrUnknownBackend, scheme, strings.Join(Backends(), ", "))
+	}
+
+	return open(u)
+}
+
+// dsnPath returns the location part of a DSN, accepting both the opaque
+// form "sqlite:pomo.db" and the hierarchical form "sqlite:///tmp/pomo.db".
+func dsnPath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+
+	return u.Host + u.Path
+}
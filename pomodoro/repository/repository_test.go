@@ -0,0 +1,79 @@
+package repository_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+	backends := map[string]repotest.Factory{
+		"memory": func(t *testing.T) pomodoro.Repository {
+			return repository.NewInMemoryRepo()
+		},
+		"sqlite": func(t *testing.T) pomodoro.Repository {
+			r, err := repository.NewSQLiteRepo(filepath.Join(t.TempDir(), "pomo.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return r
+		},
+		"json": func(t *testing.T) pomodoro.Repository {
+			r, err := repository.NewFileRepo(filepath.Join(t.TempDir(), "pomo.ndjson"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { r.Close() })
+
+			return r
+		},
+	}
+
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			repotest.Run(t, newRepo)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		dsn    string
+		expErr bool
+	}{
+		{"memory", false},
+		{"memory:", false},
+		{"sqlite:" + filepath.Join(dir, "a.db"), false},
+		{"sqlite://" + filepath.Join(dir, "b.db"), false},
+		{"json:" + filepath.Join(dir, "c.ndjson"), false},
+		{"sqlite", true},
+		{"bogus:pomo.db", true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.dsn, func(t *testing.T) {
+			r, err := repository.Open(tt.dsn)
+			if tt.expErr {
+				if err == nil {
+					t.Errorf("Expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error, got %q.\n", err)
+			}
+
+			if c, ok := r.(interface{ Close() error }); ok {
+				c.Close()
+			}
+		})
+	}
+}
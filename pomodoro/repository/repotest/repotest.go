@@ -0,0 +1,602 @@
+// Package repotest provides a conformance suite that every
+// pomodoro.Repository implementation is expected to pass.
+//
+// A backend test only needs a factory that returns an empty repository:
+//
+//	func TestConformance(t *testing.T) {
+//		repotest.Run(t, func(t *testing.T) pomodoro.Repository {
+//			return NewMyRepo(t.TempDir())
+//		})
+//	}
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// Factory returns a new, empty repository. Any cleanup should be registered
+// with t.Cleanup.
+type Factory func(t *testing.T) pomodoro.Repository
+
+// Run executes the whole suite, each test against a fresh repository.
+func Run(t *testing.T, newRepo Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(*testing.T, pomodoro.Repository)
+	}{
+		{"Create", testCreate},
+		{"ByID", testByID},
+		{"ByIDInvalid", testByIDInvalid},
+		{"Update", testUpdate},
+		{"UpdateInvalid", testUpdateInvalid},
+		{"UpdateIfState", testUpdateIfState},
+		{"Last", testLast},
+		{"LastEmpty", testLastEmpty},
+		{"Breaks", testBreaks},
+		{"BreaksEmpty", testBreaksEmpty},
+		{"CategorySummary", testCategorySummary},
+		{"CategorySummaryTimeZones", testCategorySummaryTimeZones},
+		{"Range", testRange},
+		{"Gaps", testGaps},
+		{"Sequence", testSequence},
+		{"Concurrent", testConcurrent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// day is a fixed local reference time away from midnight, so intervals a
+// few hours apart stay on the same calendar day.
+var day = time.Date(2025, time.March, 10, 12, 0, 0, 0, time.Local)
+
+func newInterval(category string, start time.Time, actual time.Duration) pomodoro.Interval {
+	return pomodoro.Interval{
+		StartTime:       start,
+		PlannedDuration: 25 * time.Minute,
+		ActualDuration:  actual,
+		Category:        category,
+		State:           pomodoro.StateDone,
+	}
+}
+
+func mustCreate(t *testing.T, r pomodoro.Repository, i pomodoro.Interval) pomodoro.Interval {
+	t.Helper()
+
+	id, err := r.Create(i)
+	if err != nil {
+		t.Fatalf("Create: expected no error, got %q.\n", err)
+	}
+
+	i.ID = id
+
+	return i
+}
+
+func assertEqual(t *testing.T, exp, got pomodoro.Interval) {
+	t.Helper()
+
+	if got.ID != exp.ID {
+		t.Errorf("Expected ID %d, got %d instead.\n", exp.ID, got.ID)
+	}
+	if !got.StartTime.Equal(exp.StartTime) {
+		t.Errorf("Expected start time %q, got %q instead.\n",
+			exp.StartTime, got.StartTime)
+	}
+	if got.PlannedDuration != exp.PlannedDuration {
+		t.Errorf("Expected planned duration %q, got %q instead.\n",
+			exp.PlannedDuration, got.PlannedDuration)
+	}
+	if got.ActualDuration != exp.ActualDuration {
+		t.Errorf("Expected actual duration %q, got %q instead.\n",
+			exp.ActualDuration, got.ActualDuration)
+	}
+	if got.Category != exp.Category {
+		t.Errorf("Expected category %q, got %q instead.\n",
+			exp.Category, got.Category)
+	}
+	if got.State != exp.State {
+		t.Errorf("Expected state %d, got %d instead.\n", exp.State, got.State)
+	}
+}
+
+func testCreate(t *testing.T, r pomodoro.Repository) {
+	var last int64
+
+	for k := range 3 {
+		id, err := r.Create(newInterval(pomodoro.CategoryPomodoro, day, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id <= last {
+			t.Errorf("Create %d: expected an ID above %d, got %d.\n", k, last, id)
+		}
+		last = id
+	}
+}
+
+func testByID(t *testing.T, r pomodoro.Repository) {
+	exp := mustCreate(t, r, pomodoro.Interval{
+		StartTime:       day.Add(1500 * time.Millisecond),
+		PlannedDuration: 5 * time.Minute,
+		ActualDuration:  90 * time.Second,
+		Category:        pomodoro.CategoryShortBreak,
+		State:           pomodoro.StatePaused,
+	})
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day, 0))
+
+	got, err := r.ByID(exp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, exp, got)
+}
+
+func testByIDInvalid(t *testing.T, r pomodoro.Repository) {
+	i := mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day, 0))
+
+	for _, id := range []int64{0, -1, i.ID + 1000} {
+		t.Run(fmt.Sprint(id), func(t *testing.T) {
+			_, err := r.ByID(id)
+			if !errors.Is(err, pomodoro.ErrInvalidID) {
+				t.Errorf("Expected error %q, got %v instead.\n",
+					pomodoro.ErrInvalidID, err)
+			}
+		})
+	}
+}
+
+func testUpdate(t *testing.T, r pomodoro.Repository) {
+	i := mustCreate(t, r, pomodoro.Interval{
+		PlannedDuration: 25 * time.Minute,
+		Category:        pomodoro.CategoryPomodoro,
+	})
+
+	i.StartTime = day
+	i.PlannedDuration = 30 * time.Minute
+	i.ActualDuration = 10 * time.Second
+	i.State = pomodoro.StateRunning
+
+	if err := r.Update(i); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, i, got)
+}
+
+func testUpdateInvalid(t *testing.T, r pomodoro.Repository) {
+	i := mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day, 0))
+
+	for _, id := range []int64{0, -1, i.ID + 1000} {
+		t.Run(fmt.Sprint(id), func(t *testing.T) {
+			u := i
+			u.ID = id
+
+			err := r.Update(u)
+			if !errors.Is(err, pomodoro.ErrInvalidID) {
+				t.Errorf("Expected error %q, got %v instead.\n",
+					pomodoro.ErrInvalidID, err)
+			}
+		})
+	}
+
+	got, err := r.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, i, got)
+}
+
+// testUpdateIfState only applies to repositories implementing
+// pomodoro.StateUpdater.
+func testUpdateIfState(t *testing.T, r pomodoro.Repository) {
+	su, ok := r.(pomodoro.StateUpdater)
+	if !ok {
+		t.Skip("Skipped: repository is not a pomodoro.StateUpdater")
+	}
+
+	i := newInterval(pomodoro.CategoryPomodoro, day, 0)
+	i.State = pomodoro.StateRunning
+	i = mustCreate(t, r, i)
+
+	paused := i
+	paused.State = pomodoro.StatePaused
+	if err := r.Update(paused); err != nil {
+		t.Fatal(err)
+	}
+
+	i.ActualDuration = time.Second
+	i.PlannedDuration = time.Hour
+	updated, err := su.UpdateIfState(i, pomodoro.StateRunning)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated {
+		t.Error("Expected no update while the stored state differs")
+	}
+
+	got, err := r.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqual(t, paused, got)
+
+	if updated, err = su.UpdateIfState(i, pomodoro.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+
+	if !updated {
+		t.Error("Expected update while the stored state matches")
+	}
+
+	if got, err = r.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The planned duration is not UpdateIfState's to change.
+	i.PlannedDuration = paused.PlannedDuration
+	assertEqual(t, i, got)
+}
+
+func testLast(t *testing.T, r pomodoro.Repository) {
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day, 0))
+	exp := mustCreate(t, r, newInterval(pomodoro.CategoryShortBreak, day, 0))
+
+	got, err := r.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertEqual(t, exp, got)
+}
+
+func testLastEmpty(t *testing.T, r pomodoro.Repository) {
+	_, err := r.Last()
+	if !errors.Is(err, pomodoro.ErrNoInterval) {
+		t.Errorf("Expected error %q, got %v instead.\n",
+			pomodoro.ErrNoInterval, err)
+	}
+}
+
+func testBreaks(t *testing.T, r pomodoro.Repository) {
+	categories := []string{
+		pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro,
+		pomodoro.CategoryLongBreak,
+		pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro,
+	}
+
+	var breaks []pomodoro.Interval
+	for _, c := range categories {
+		i := mustCreate(t, r, newInterval(c, day, 0))
+		if c != pomodoro.CategoryPomodoro {
+			breaks = append([]pomodoro.Interval{i}, breaks...)
+		}
+	}
+
+	for _, n := range []int{1, 2, 3, 10} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			got, err := r.Breaks(n)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exp := breaks[:min(n, len(breaks))]
+			if len(got) != len(exp) {
+				t.Fatalf("Expected %d breaks, got %d instead.\n", len(exp), len(got))
+			}
+
+			for k := range exp {
+				assertEqual(t, exp[k], got[k])
+			}
+		})
+	}
+}
+
+func testBreaksEmpty(t *testing.T, r pomodoro.Repository) {
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day, 0))
+
+	got, err := r.Breaks(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("Expected no breaks, got %d instead.\n", len(got))
+	}
+}
+
+func testCategorySummary(t *testing.T, r pomodoro.Repository) {
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day, 25*time.Minute))
+	mustCreate(t, r, newInterval(pomodoro.CategoryShortBreak, day.Add(time.Hour), 5*time.Minute))
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day.Add(2*time.Hour), 20*time.Minute))
+	mustCreate(t, r, newInterval(pomodoro.CategoryLongBreak, day.Add(3*time.Hour), 15*time.Minute))
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day.AddDate(0, 0, 1), 25*time.Minute))
+
+	testCases := []struct {
+		filter string
+		day    time.Time
+		exp    time.Duration
+	}{
+		{pomodoro.CategoryPomodoro, day, 45 * time.Minute},
+		{"%Break", day, 20 * time.Minute},
+		{pomodoro.CategoryShortBreak, day, 5 * time.Minute},
+		{pomodoro.CategoryPomodoro, day.AddDate(0, 0, 1), 25 * time.Minute},
+		{"%Break", day.AddDate(0, 0, 1), 0},
+		{pomodoro.CategoryPomodoro, day.AddDate(0, 0, -1), 0},
+	}
+
+	for _, tt := range testCases {
+		name := fmt.Sprintf("%s/%s", tt.filter, tt.day.Format("2006-01-02"))
+		t.Run(name, func(t *testing.T) {
+			got, err := r.CategorySummary(tt.day, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.exp {
+				t.Errorf("Expected %q, got %q instead.\n", tt.exp, got)
+			}
+		})
+	}
+}
+
+// testCategorySummaryTimeZones checks that days are compared in local time,
+// whatever zone the interval start or the queried day are expressed in.
+func testCategorySummaryTimeZones(t *testing.T, r pomodoro.Repository) {
+	east := time.FixedZone("UTC+14", 14*60*60)
+	west := time.FixedZone("UTC-12", -12*60*60)
+
+	start := time.Date(2025, time.March, 10, 23, 30, 0, 0, east)
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, start, 25*time.Minute))
+
+	local := start.Local()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name string
+		day  time.Time
+		exp  time.Duration
+	}{
+		{"SameInstant", start, 25 * time.Minute},
+		{"SameInstantOtherZone", start.In(west), 25 * time.Minute},
+		{"LocalMidnight", midnight, 25 * time.Minute},
+		{"LocalLastSecond", midnight.Add(24*time.Hour - time.Second), 25 * time.Minute},
+		{"NextLocalDay", midnight.AddDate(0, 0, 1), 0},
+		{"PreviousLocalDay", midnight.Add(-time.Second), 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.CategorySummary(tt.day, pomodoro.CategoryPomodoro)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.exp {
+				t.Errorf("Expected %q, got %q instead.\n", tt.exp, got)
+			}
+		})
+	}
+}
+
+func testRange(t *testing.T, r pomodoro.Repository) {
+	east := time.FixedZone("UTC+10", 10*60*60)
+
+	// Created out of start order on purpose.
+	late := mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day.Add(2*time.Hour), 0))
+	early := mustCreate(t, r, newInterval(pomodoro.CategoryShortBreak, day.In(east), 0))
+	mustCreate(t, r, pomodoro.Interval{Category: pomodoro.CategoryPomodoro})
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day.AddDate(0, 0, 1), 0))
+	mustCreate(t, r, newInterval(pomodoro.CategoryPomodoro, day.Add(-time.Nanosecond*1000), 0))
+
+	testCases := []struct {
+		name     string
+		from, to time.Time
+		exp      []pomodoro.Interval
+	}{
+		{"Day", day, day.Add(12 * time.Hour), []pomodoro.Interval{early, late}},
+		{"FromInclusive", day, day.Add(2 * time.Hour), []pomodoro.Interval{early}},
+		{"ToExclusive", day.Add(time.Second), day.Add(2*time.Hour + time.Second), []pomodoro.Interval{late}},
+		{"OtherZone", day.In(east), day.Add(time.Hour).In(time.UTC), []pomodoro.Interval{early}},
+		{"Empty", day.AddDate(0, 0, 2), day.AddDate(0, 0, 3), nil},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Range(tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != len(tt.exp) {
+				t.Fatalf("Expected %d intervals, got %d instead.\n", len(tt.exp), len(got))
+			}
+
+			for k := range tt.exp {
+				assertEqual(t, tt.exp[k], got[k])
+			}
+		})
+	}
+
+	all, err := r.Range(time.Time{}, day.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 4 {
+		t.Errorf("Expected 4 started intervals, got %d instead.\n", len(all))
+	}
+}
+
+// testGaps only applies to repositories implementing pomodoro.GapRecorder.
+func testGaps(t *testing.T, r pomodoro.Repository) {
+	gr, ok := r.(pomodoro.GapRecorder)
+	if !ok {
+		t.Skip("Skipped: repository is not a pomodoro.GapRecorder")
+	}
+
+	// Created out of start order on purpose.
+	gaps := []pomodoro.Gap{
+		{StartTime: day.Add(2 * time.Hour), EndTime: day.Add(2*time.Hour + 10*time.Minute)},
+		{StartTime: day, EndTime: day.Add(40 * time.Minute)},
+		{StartTime: day.AddDate(0, 0, 1), EndTime: day.AddDate(0, 0, 1).Add(time.Minute)},
+	}
+
+	for k, g := range gaps {
+		id, err := gr.CreateGap(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != int64(k+1) {
+			t.Errorf("Expected ID %d, got %d instead.\n", k+1, id)
+		}
+	}
+
+	// Recorded by another process a second later.
+	dup := pomodoro.Gap{StartTime: day.Add(time.Second), EndTime: day.Add(40*time.Minute + time.Second)}
+	id, err := gr.CreateGap(dup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id != 0 {
+		t.Errorf("Expected an overlapping gap to be ignored, got ID %d instead.\n", id)
+	}
+
+	got, err := gr.Gaps(day, day.Add(12*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []time.Duration{40 * time.Minute, 10 * time.Minute}
+	if len(got) != len(exp) {
+		t.Fatalf("Expected %d gaps, got %d instead.\n", len(exp), len(got))
+	}
+
+	for k, g := range got {
+		if g.Duration() != exp[k] {
+			t.Errorf("Expected gap %d to last %s, got %s instead.\n", k, exp[k], g.Duration())
+		}
+	}
+}
+
+// testSequence walks through the category rotation driven by Last and
+// Breaks: four pomodoros with short breaks, then a long break.
+func testSequence(t *testing.T, r pomodoro.Repository) {
+	config := pomodoro.NewConfig(r, 3*time.Minute, time.Minute, 2*time.Minute)
+
+	exp := []string{
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro, pomodoro.CategoryLongBreak,
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak,
+	}
+
+	for k, category := range exp {
+		i, err := pomodoro.GetInterval(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i.Category != category {
+			t.Fatalf("Interval %d: expected category %q, got %q instead.\n",
+				k+1, category, i.Category)
+		}
+
+		i.State = pomodoro.StateDone
+		if err := r.Update(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testConcurrent(t *testing.T, r pomodoro.Repository) {
+	const workers, perWorker = 8, 10
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		ids  = map[int64]bool{}
+		errs = make(chan error, workers*perWorker)
+	)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range perWorker {
+				i := newInterval(pomodoro.CategoryPomodoro, day, time.Second)
+
+				id, err := r.Create(i)
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				mu.Lock()
+				dup := ids[id]
+				ids[id] = true
+				mu.Unlock()
+
+				if dup {
+					errs <- fmt.Errorf("duplicate ID %d", id)
+				}
+
+				i.ID = id
+				i.ActualDuration = 2 * time.Second
+				if err := r.Update(i); err != nil {
+					errs <- err
+				}
+
+				if _, err := r.Last(); err != nil {
+					errs <- err
+				}
+				if _, err := r.Breaks(2); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	got, err := r.CategorySummary(day, pomodoro.CategoryPomodoro)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := workers * perWorker * 2 * time.Second; got != exp {
+		t.Errorf("Expected %q, got %q instead.\n", exp, got)
+	}
+}
@@ -1,11 +1,12 @@
-//go:build !inmemory
-// +build !inmemory
-
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,13 +25,54 @@ CREATE TABLE IF NOT EXISTS "interval" (
 	PRIMARY KEY("id")
 );`
 
+const createTableGap string = `
+CREATE TABLE IF NOT EXISTS "gap" (
+	"id" INTEGER,
+	"start_time" DATETIME NOT NULL,
+	"end_time" DATETIME NOT NULL,
+	PRIMARY KEY("id")
+);`
+
+func init() {
+	open := func(dsn *url.URL) (pomodoro.Repository, error) {
+		dbfile := dsnPath(dsn)
+		if dbfile == "" {
+			return nil, errors.New("sqlite: missing database file")
+		}
+		if dsn.RawQuery != "" {
+			dbfile += "?" + dsn.RawQuery
+		}
+
+		return NewSQLiteRepo(dbfile)
+	}
+
+	Register("sqlite", open)
+	Register("sqlite3", open)
+}
+
+// sqliteOptions lets several processes share the database: WAL keeps
+// readers and the writer out of each other's way, and the busy timeout
+// makes a writer wait for the lock instead of failing with
+// "database is locked".
+const sqliteOptions = "_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+
 type dbRepo struct {
 	db *sql.DB
 	sync.RWMutex
+
+	// dataVersion is the PRAGMA data_version last read on the connection
+	// dataConn.
+	dataVersion int64
+	dataConn    any
 }
 
 func NewSQLiteRepo(dbfile string) (*dbRepo, error) {
-	db, err := sql.Open("sqlite3", dbfile)
+	sep := "?"
+	if strings.Contains(dbfile, "?") {
+		sep = "&"
+	}
+
+	db, err := sql.Open("sqlite3", dbfile+sep+sqliteOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -46,9 +88,19 @@ func NewSQLiteRepo(dbfile string) (*dbRepo, error) {
 		return nil, err
 	}
 
-	return &dbRepo{
+	if _, err := db.Exec(createTableGap); err != nil {
+		return nil, err
+	}
+
+	r := &dbRepo{
 		db: db,
-	}, nil
+	}
+
+	if _, err := r.Changed(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
 }
 
 func (r *dbRepo) Create(i pomodoro.Interval) (int64, error) {
@@ -87,7 +139,8 @@ func (r *dbRepo) Update(i pomodoro.Interval) error {
 	defer r.Unlock()
 
 	query := `
-	UPDATE interval SET start_time=?, actual_duration=?, state=?
+	UPDATE interval SET start_time=?, planned_duration=?, actual_duration=?,
+	category=?, state=?
 	WHERE id=?`
 	stmt, err := r.db.Prepare(query)
 	if err != nil {
@@ -97,7 +150,9 @@ func (r *dbRepo) Update(i pomodoro.Interval) error {
 
 	args := []any{
 		i.StartTime,
+		i.PlannedDuration,
 		i.ActualDuration,
+		i.Category,
 		i.State,
 		i.ID,
 	}
@@ -106,9 +161,71 @@ func (r *dbRepo) Update(i pomodoro.Interval) error {
 		return err
 	}
 
-	_, err = res.RowsAffected()
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+	}
 
-	return err
+	return nil
+}
+
+// UpdateIfState updates i only if its stored state is still state. The
+// planned duration is left as stored.
+func (r *dbRepo) UpdateIfState(i pomodoro.Interval, state int) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	query := `
+	UPDATE interval SET start_time=?, actual_duration=?, state=?
+	WHERE id=? AND state=?`
+
+	res, err := r.db.Exec(query, i.StartTime, i.ActualDuration, i.State, i.ID, state)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+// Changed reports whether another connection committed to the database
+// since the last call, based on PRAGMA data_version. The baseline is taken
+// when the repository is opened, and again whenever the pool replaced the
+// connection, since the version is only comparable on one connection.
+func (r *dbRepo) Changed() (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ctx := context.Background()
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var dc any
+	if err := conn.Raw(func(c any) error {
+		dc = c
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	var v int64
+	if err := conn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&v); err != nil {
+		return false, err
+	}
+
+	changed := dc == r.dataConn && v != r.dataVersion
+	r.dataVersion, r.dataConn = v, dc
+
+	return changed, nil
 }
 
 func (r *dbRepo) ByID(id int64) (pomodoro.Interval, error) {
@@ -127,6 +244,10 @@ func (r *dbRepo) ByID(id int64) (pomodoro.Interval, error) {
 		&i.State,
 	)
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return i, fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+	}
+
 	return i, err
 }
 
@@ -171,6 +292,7 @@ func (r *dbRepo) Breaks(n int) ([]pomodoro.Interval, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var i pomodoro.Interval
@@ -216,3 +338,112 @@ func (r *dbRepo) CategorySummary(day time.Time, filter string) (time.Duration, e
 
 	return d, err
 }
+
+func (r *dbRepo) Range(from, to time.Time) ([]pomodoro.Interval, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	// SQLite date functions stop at milliseconds, so select with a margin
+	// and apply the exact bounds below.
+	query := `
+	SELECT * FROM interval
+	WHERE unixepoch(start_time) >= unixepoch(?) - 1 AND
+	unixepoch(start_time) <= unixepoch(?) + 1
+	ORDER BY id`
+
+	var intervals []pomodoro.Interval
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i pomodoro.Interval
+		err := rows.Scan(
+			&i.ID,
+			&i.StartTime,
+			&i.PlannedDuration,
+			&i.ActualDuration,
+			&i.Category,
+			&i.State,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return inRange(intervals, from, to), nil
+}
+
+// CreateGap stores g unless it overlaps a stored gap. Overlaps are
+// checked to the second.
+func (r *dbRepo) CreateGap(g pomodoro.Gap) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	query := `
+	INSERT INTO gap SELECT NULL, ?, ?
+	WHERE NOT EXISTS (
+		SELECT 1 FROM gap
+		WHERE unixepoch(start_time) < unixepoch(?) AND
+		unixepoch(end_time) > unixepoch(?)
+	)`
+
+	res, err := r.db.Exec(query, g.StartTime, g.EndTime, g.EndTime, g.StartTime)
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *dbRepo) Gaps(from, to time.Time) ([]pomodoro.Gap, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	// Same margin as Range.
+	query := `
+	SELECT * FROM gap
+	WHERE unixepoch(start_time) >= unixepoch(?) - 1 AND
+	unixepoch(start_time) <= unixepoch(?) + 1
+	ORDER BY id`
+
+	var gaps []pomodoro.Gap
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g pomodoro.Gap
+		if err := rows.Scan(&g.ID, &g.StartTime, &g.EndTime); err != nil {
+			return nil, err
+		}
+
+		gaps = append(gaps, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return gapsInRange(gaps, from, to), nil
+}
+
+func (r *dbRepo) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+var ErrNotPomoDB = errors.New("Not a pomo database")
+
+// MergeResult summarizes a Merge.
+type MergeResult struct {
+	Imported int
+	Skipped  int
+	// Cancelled counts the imported intervals that were running or paused
+	// in the src database.
+	Cancelled int
+}
+
+// Backup writes a consistent snapshot of the database to dest with
+// VACUUM INTO. It is safe to run while other connections are writing.
+func (r *dbRepo) Backup(dest string) error {
+	r.RLock()
+	defer r.RUnlock()
+
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s: %w", dest, os.ErrExist)
+	}
+
+	_, err := r.db.Exec("VACUUM INTO ?", dest)
+
+	return err
+}
+
+// Restore replaces every interval and gap with the ones stored in the src
+// database, in a single transaction. A backup taken before gaps were
+// recorded leaves none. Restoring while an interval is running is refused.
+func (r *dbRepo) Restore(src string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.withAttached(src, func(tx *sql.Tx) error {
+		existing, err := queryIntervals(tx, "SELECT * FROM main.interval ORDER BY id DESC LIMIT 1")
+		if err != nil {
+			return err
+		}
+
+		if len(existing) > 0 && existing[0].State == pomodoro.StateRunning {
+			return fmt.Errorf("%w: stop interval %d before restoring",
+				pomodoro.ErrInvalidState, existing[0].ID)
+		}
+
+		if _, err := tx.Exec("DELETE FROM interval"); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(`
+		INSERT INTO interval
+		SELECT id, start_time, planned_duration, actual_duration, category, state
+		FROM src.interval`); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec("DELETE FROM gap"); err != nil {
+			return err
+		}
+
+		ok, err := hasSrcTable(tx, "gap")
+		if err != nil || !ok {
+			return err
+		}
+
+		_, err = tx.Exec("INSERT INTO gap SELECT id, start_time, end_time FROM src.gap")
+
+		return err
+	})
+}
+
+// Merge imports the intervals of the src database. Intervals that were never
+// started or that overlap one already present are skipped, and the rest are
+// appended under new IDs. Intervals still running or paused in src are
+// imported as cancelled, so that none is resumed on this machine. Gaps are
+// appended the same way, skipping those that overlap a gap or an interval
+// already present.
+//
+// Intervals are ordered by ID, so the latest interval is rewritten after
+// the imported ones: an unfinished interval stays the one GetInterval
+// resumes, and a finished one is placed in start order among them. Merging
+// while an interval is running is refused.
+func (r *dbRepo) Merge(src string) (MergeResult, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var res MergeResult
+
+	err := r.withAttached(src, func(tx *sql.Tx) error {
+		existing, err := queryIntervals(tx, "SELECT * FROM main.interval ORDER BY id")
+		if err != nil {
+			return err
+		}
+
+		incoming, err := queryIntervals(tx, "SELECT * FROM src.interval")
+		if err != nil {
+			return err
+		}
+
+		var last *pomodoro.Interval
+		active := false
+		if n := len(existing); n > 0 {
+			last = &existing[n-1]
+
+			switch last.State {
+			case pomodoro.StateRunning:
+				return fmt.Errorf("%w: stop interval %d before merging",
+					pomodoro.ErrInvalidState, last.ID)
+			case pomodoro.StateNotStarted, pomodoro.StatePaused:
+				active = true
+			}
+		}
+
+		var write []pomodoro.Interval
+
+		for _, i := range incoming {
+			if i.StartTime.IsZero() || overlapsAny(i, existing) {
+				res.Skipped++
+				continue
+			}
+
+			if i.State == pomodoro.StateRunning || i.State == pomodoro.StatePaused {
+				i.State = pomodoro.StateCancelled
+				res.Cancelled++
+			}
+
+			existing = append(existing, i)
+			write = append(write, i)
+			res.Imported++
+		}
+
+		if err := mergeGaps(tx, existing); err != nil {
+			return err
+		}
+
+		if len(write) == 0 {
+			return nil
+		}
+
+		if last != nil && !active {
+			write = append(write, *last)
+		}
+
+		sort.SliceStable(write, func(a, b int) bool {
+			return write[a].StartTime.Before(write[b].StartTime)
+		})
+
+		if active {
+			write = append(write, *last)
+		}
+
+		insert, err := tx.Prepare("INSERT INTO interval VALUES(NULL, ?, ?, ?, ?, ?)")
+		if err != nil {
+			return err
+		}
+		defer insert.Close()
+
+		for _, i := range write {
+			_, err := insert.Exec(
+				i.StartTime,
+				i.PlannedDuration,
+				i.ActualDuration,
+				i.Category,
+				i.State,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if last == nil {
+			return nil
+		}
+
+		_, err = tx.Exec("DELETE FROM interval WHERE id=?", last.ID)
+
+		return err
+	})
+
+	return res, err
+}
+
+// withAttached attaches the src database as "src" and runs fn in a
+// transaction on the same connection.
+func (r *dbRepo) withAttached(src string, fn func(*sql.Tx) error) error {
+	// ATTACH silently creates missing files.
+	if _, err := os.Stat(src); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS src", src); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "DETACH DATABASE src")
+
+	var n int
+	err = conn.QueryRowContext(ctx, `
+	SELECT count(*) FROM src.sqlite_master
+	WHERE type='table' AND name='interval'`).Scan(&n)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", src, ErrNotPomoDB, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", src, ErrNotPomoDB)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// mergeGaps appends the gaps of the src database that overlap neither a gap
+// of the main one nor one of intervals.
+func mergeGaps(tx *sql.Tx, intervals []pomodoro.Interval) error {
+	ok, err := hasSrcTable(tx, "gap")
+	if err != nil || !ok {
+		return err
+	}
+
+	existing, err := queryGaps(tx, "SELECT * FROM main.gap")
+	if err != nil {
+		return err
+	}
+
+	incoming, err := queryGaps(tx, "SELECT * FROM src.gap ORDER BY start_time")
+	if err != nil {
+		return err
+	}
+
+	insert, err := tx.Prepare("INSERT INTO gap VALUES(NULL, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	for _, g := range incoming {
+		if gapOverlaps(g, existing, intervals) {
+			continue
+		}
+
+		if _, err := insert.Exec(g.StartTime, g.EndTime); err != nil {
+			return err
+		}
+
+		existing = append(existing, g)
+	}
+
+	return nil
+}
+
+// hasSrcTable reports whether the attached src database has the table name.
+func hasSrcTable(tx *sql.Tx, name string) (bool, error) {
+	var n int
+	err := tx.QueryRow(`
+	SELECT count(*) FROM src.sqlite_master
+	WHERE type='table' AND name=?`, name).Scan(&n)
+
+	return n > 0, err
+}
+
+func queryIntervals(tx *sql.Tx, query string) ([]pomodoro.Interval, error) {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var intervals []pomodoro.Interval
+
+	for rows.Next() {
+		var i pomodoro.Interval
+		err := rows.Scan(
+			&i.ID,
+			&i.StartTime,
+			&i.PlannedDuration,
+			&i.ActualDuration,
+			&i.Category,
+			&i.State,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		intervals = append(intervals, i)
+	}
+
+	return intervals, rows.Err()
+}
+
+func queryGaps(tx *sql.Tx, query string) ([]pomodoro.Gap, error) {
+	rows, err := tx.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gaps []pomodoro.Gap
+
+	for rows.Next() {
+		var g pomodoro.Gap
+		if err := rows.Scan(&g.ID, &g.StartTime, &g.EndTime); err != nil {
+			return nil, err
+		}
+
+		gaps = append(gaps, g)
+	}
+
+	return gaps, rows.Err()
+}
+
+func gapOverlaps(g pomodoro.Gap, gaps []pomodoro.Gap, intervals []pomodoro.Interval) bool {
+	for _, o := range gaps {
+		if g.StartTime.Before(o.EndTime) && o.StartTime.Before(g.EndTime) {
+			return true
+		}
+	}
+
+	for _, i := range intervals {
+		if !i.StartTime.IsZero() && g.StartTime.Before(i.EndTime()) && i.StartTime.Before(g.EndTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func overlapsAny(i pomodoro.Interval, intervals []pomodoro.Interval) bool {
+	for _, o := range intervals {
+		if !o.StartTime.IsZero() && i.Overlaps(o) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,271 @@
+package repository_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+type maintainer interface {
+	pomodoro.Repository
+	pomodoro.GapRecorder
+	Backup(string) error
+	Restore(string) error
+	Merge(string) (repository.MergeResult, error)
+}
+
+func newSQLiteRepo(t *testing.T, path string, intervals ...pomodoro.Interval) maintainer {
+	t.Helper()
+
+	r, err := repository.NewSQLiteRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+
+	for _, i := range intervals {
+		if _, err := r.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return r
+}
+
+func done(category string, start time.Time, d time.Duration) pomodoro.Interval {
+	return pomodoro.Interval{
+		StartTime:       start,
+		PlannedDuration: d,
+		ActualDuration:  d,
+		Category:        category,
+		State:           pomodoro.StateDone,
+	}
+}
+
+func createGaps(t *testing.T, r maintainer, gaps ...pomodoro.Gap) {
+	t.Helper()
+
+	for _, g := range gaps {
+		if _, err := r.CreateGap(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func gapsOn(t *testing.T, r maintainer, day time.Time) []pomodoro.Gap {
+	t.Helper()
+
+	gaps, err := r.Gaps(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return gaps
+}
+
+func TestSQLiteBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.Local)
+
+	r := newSQLiteRepo(t, filepath.Join(dir, "pomo.db"),
+		done(pomodoro.CategoryPomodoro, start, 25*time.Minute),
+		done(pomodoro.CategoryShortBreak, start.Add(25*time.Minute), 5*time.Minute),
+	)
+	createGaps(t, r, pomodoro.Gap{StartTime: start.Add(30 * time.Minute), EndTime: start.Add(40 * time.Minute)})
+
+	backup := filepath.Join(dir, "backup.db")
+	if err := r.Backup(backup); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Backup(backup); !errors.Is(err, os.ErrExist) {
+		t.Errorf("Expected error %q, got %v instead.\n", os.ErrExist, err)
+	}
+
+	if _, err := r.Create(done(pomodoro.CategoryPomodoro, start.Add(time.Hour), time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	createGaps(t, r, pomodoro.Gap{StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)})
+
+	if err := r.Restore(backup); err != nil {
+		t.Fatal(err)
+	}
+
+	if gaps := gapsOn(t, r, start); len(gaps) != 1 || !gaps[0].StartTime.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("Expected the gap of the backup only, got %+v instead.\n", gaps)
+	}
+
+	last, err := r.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last.ID != 2 || last.Category != pomodoro.CategoryShortBreak {
+		t.Errorf("Expected interval 2 %q, got %d %q instead.\n",
+			pomodoro.CategoryShortBreak, last.ID, last.Category)
+	}
+
+	notDB := filepath.Join(dir, "empty.db")
+	if err := os.WriteFile(notDB, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Restore(filepath.Join(dir, "missing.db")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected error %q, got %v instead.\n", os.ErrNotExist, err)
+	}
+
+	if err := r.Restore(notDB); !errors.Is(err, repository.ErrNotPomoDB) {
+		t.Errorf("Expected error %q, got %v instead.\n", repository.ErrNotPomoDB, err)
+	}
+	last.State = pomodoro.StateRunning
+	if err := r.Update(last); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Restore(backup); !errors.Is(err, pomodoro.ErrInvalidState) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrInvalidState, err)
+	}
+}
+
+func TestSQLiteMerge(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.Local)
+
+	paused := done(pomodoro.CategoryPomodoro, start.Add(3*time.Hour), 10*time.Minute)
+	paused.State = pomodoro.StatePaused
+
+	laptop := newSQLiteRepo(t, filepath.Join(dir, "laptop.db"),
+		done(pomodoro.CategoryPomodoro, start, 25*time.Minute),
+		paused,
+	)
+	createGaps(t, laptop, pomodoro.Gap{StartTime: start.Add(30 * time.Minute), EndTime: start.Add(50 * time.Minute)})
+
+	desktop := filepath.Join(dir, "desktop.db")
+	desktopRepo := newSQLiteRepo(t, desktop,
+		// Same pomodoro recorded on both machines.
+		done(pomodoro.CategoryPomodoro, start, 25*time.Minute),
+		// Overlaps the first one.
+		done(pomodoro.CategoryShortBreak, start.Add(20*time.Minute), 5*time.Minute),
+		// Never started.
+		pomodoro.Interval{Category: pomodoro.CategoryPomodoro},
+		done(pomodoro.CategoryPomodoro, start.Add(time.Hour), 25*time.Minute),
+		done(pomodoro.CategoryShortBreak, start.Add(time.Hour+25*time.Minute), 5*time.Minute),
+	)
+	createGaps(t, desktopRepo,
+		// Overlaps the gap of the laptop.
+		pomodoro.Gap{StartTime: start.Add(35 * time.Minute), EndTime: start.Add(45 * time.Minute)},
+		// Overlaps an imported pomodoro.
+		pomodoro.Gap{StartTime: start.Add(time.Hour + 10*time.Minute), EndTime: start.Add(time.Hour + 15*time.Minute)},
+		pomodoro.Gap{StartTime: start.Add(2 * time.Hour), EndTime: start.Add(2*time.Hour + 30*time.Minute)},
+	)
+
+	res, err := laptop.Merge(desktop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Imported != 2 || res.Skipped != 3 {
+		t.Errorf("Expected 2 imported and 3 skipped, got %d and %d instead.\n",
+			res.Imported, res.Skipped)
+	}
+
+	sum, err := laptop.CategorySummary(start, pomodoro.CategoryPomodoro)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := 60 * time.Minute; sum != exp {
+		t.Errorf("Expected pomodoro total %q, got %q instead.\n", exp, sum)
+	}
+
+	last, err := laptop.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last.State != pomodoro.StatePaused || last.ID != 5 {
+		t.Errorf("Expected paused interval to move to ID 5, got %d in state %d.\n",
+			last.ID, last.State)
+	}
+
+	// Merging again imports nothing new.
+	if res, err = laptop.Merge(desktop); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Imported != 0 {
+		t.Errorf("Expected nothing imported, got %d instead.\n", res.Imported)
+	}
+
+	gaps := gapsOn(t, laptop, start)
+	if len(gaps) != 2 || !gaps[1].StartTime.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Expected the laptop gap and the last desktop gap, got %+v instead.\n", gaps)
+	}
+
+	last.State = pomodoro.StateRunning
+	if err := laptop.Update(last); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := laptop.Merge(desktop); !errors.Is(err, pomodoro.ErrInvalidState) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrInvalidState, err)
+	}
+}
+
+func TestSQLiteMergeAfterDone(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2025, time.March, 10, 9, 0, 0, 0, time.Local)
+
+	// The laptop ended the day with a short break.
+	laptop := newSQLiteRepo(t, filepath.Join(dir, "laptop.db"),
+		done(pomodoro.CategoryPomodoro, start.Add(5*time.Hour), 25*time.Minute),
+		done(pomodoro.CategoryShortBreak, start.Add(5*time.Hour+25*time.Minute), 5*time.Minute),
+	)
+
+	running := done(pomodoro.CategoryPomodoro, start.Add(time.Hour), 10*time.Minute)
+	running.State = pomodoro.StateRunning
+
+	desktop := filepath.Join(dir, "desktop.db")
+	newSQLiteRepo(t, desktop,
+		done(pomodoro.CategoryPomodoro, start, 25*time.Minute),
+		// Left running when the desktop was switched off.
+		running,
+	)
+
+	res, err := laptop.Merge(desktop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Imported != 2 || res.Cancelled != 1 {
+		t.Errorf("Expected 2 imported and 1 cancelled, got %d and %d instead.\n",
+			res.Imported, res.Cancelled)
+	}
+
+	last, err := laptop.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if last.Category != pomodoro.CategoryShortBreak || last.State != pomodoro.StateDone {
+		t.Errorf("Expected the laptop short break to stay last, got %+v instead.\n", last)
+	}
+
+	intervals, err := laptop.Range(start, start.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(intervals) != 4 {
+		t.Fatalf("Expected 4 intervals, got %d instead.\n", len(intervals))
+	}
+
+	if intervals[1].State != pomodoro.StateCancelled {
+		t.Errorf("Expected the desktop interval cancelled, got state %d instead.\n", intervals[1].State)
+	}
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "github.com/ZeroBl21/go-ztimer/pomodoro"
+
+// DropIdleConns makes the SQLite repository r close its connection after
+// every use, as the pool does once it sat idle for too long.
+func DropIdleConns(r pomodoro.Repository) {
+	r.(*dbRepo).db.SetMaxIdleConns(0)
+}
@@ -0,0 +1,149 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
+)
+
+func TestSQLiteChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.db")
+
+	r1 := newSQLiteRepo(t, path)
+	r2 := newSQLiteRepo(t, path)
+
+	cd := r1.(pomodoro.ChangeDetector)
+
+	if changed, err := cd.Changed(); err != nil || changed {
+		t.Fatalf("Expected no change, got %t, %v.\n", changed, err)
+	}
+
+	// Our own writes are not reported.
+	if _, err := r1.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := cd.Changed(); err != nil || changed {
+		t.Errorf("Expected own write to be ignored, got %t, %v.\n", changed, err)
+	}
+
+	if _, err := r2.Create(pomodoro.Interval{Category: pomodoro.CategoryShortBreak}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := cd.Changed(); err != nil || !changed {
+		t.Errorf("Expected change from other connection, got %t, %v.\n", changed, err)
+	}
+
+	if changed, err := cd.Changed(); err != nil || changed {
+		t.Errorf("Expected change to be reported once, got %t, %v.\n", changed, err)
+	}
+}
+
+func TestSQLiteChangedNewConn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.db")
+
+	r1 := newSQLiteRepo(t, path)
+	r2 := newSQLiteRepo(t, path)
+
+	cd := r1.(pomodoro.ChangeDetector)
+
+	if _, err := r2.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed, err := cd.Changed(); err != nil || !changed {
+		t.Fatalf("Expected change from other connection, got %t, %v.\n", changed, err)
+	}
+
+	// Every call gets a new connection, whose data_version starts over.
+	repository.DropIdleConns(r1)
+
+	for n := 0; n < 2; n++ {
+		if changed, err := cd.Changed(); err != nil || changed {
+			t.Errorf("Expected no change on a new connection, got %t, %v.\n", changed, err)
+		}
+	}
+}
+
+func TestSQLiteSharedPause(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.db")
+
+	tui := pomodoro.NewConfig(newSQLiteRepo(t, path), time.Minute, time.Minute, time.Minute)
+	cli := pomodoro.NewConfig(newSQLiteRepo(t, path), time.Minute, time.Minute, time.Minute)
+
+	i, err := pomodoro.GetInterval(tui)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(pomodoro.Interval) {}
+	end := func(pomodoro.Interval) {
+		t.Error("End callback should not be executed")
+	}
+
+	paused := make(chan time.Time)
+	go func() {
+		time.Sleep(1500 * time.Millisecond)
+
+		i, err := pomodoro.GetInterval(cli)
+		if err != nil {
+			t.Error(err)
+		}
+		if err := i.Pause(cli); err != nil {
+			t.Error(err)
+		}
+
+		paused <- time.Now()
+	}()
+
+	if err := i.Start(context.Background(), tui, noop, noop, end); err != nil {
+		t.Fatal(err)
+	}
+	returned := time.Now()
+
+	if d := returned.Sub(<-paused); d > time.Second {
+		t.Errorf("Expected pause to be picked up within 1s, took %s.\n", d)
+	}
+
+	i, err = pomodoro.Current(tui)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i.State != pomodoro.StatePaused {
+		t.Errorf("Expected state %d, got %d instead.\n", pomodoro.StatePaused, i.State)
+	}
+
+	changed, err := pomodoro.Changed(tui)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !changed {
+		t.Error("Expected the pause to be reported as a change")
+	}
+}
+
+func TestSQLiteWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomo.db")
+
+	r, err := repository.NewSQLiteRepo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err := r.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path + "-wal"); err != nil {
+		t.Errorf("Expected a write-ahead log next to the database")
+	}
+}
@@ -0,0 +1,486 @@
+package pomodoro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Event types published by a Runner.
+const (
+	EventStarted   = "started"
+	EventTick      = "tick"
+	EventPaused    = "paused"
+	EventDone      = "done"
+	EventCancelled = "cancelled"
+)
+
+// Event is a change of the interval a Runner drives. Seq grows by one with
+// every event the Runner publishes.
+type Event struct {
+	Seq      uint64    `json:"seq"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	Interval Interval  `json:"interval"`
+	// Milestone is set on EventMilestone only.
+	Milestone *Milestone `json:"milestone,omitempty"`
+	// Idle is set on EventIdle only.
+	Idle *Idle `json:"idle,omitempty"`
+}
+
+// Controller drives the timer. Runner implements it in-process and the
+// daemon client over a socket, so front ends work the same with either.
+type Controller interface {
+	Status() (Interval, error)
+	Start() (Interval, error)
+	Resume() (Interval, error)
+	Pause() (Interval, error)
+	End() (Interval, error)
+	Skip() (Interval, error)
+	Extend(d time.Duration) (Interval, error)
+	// Subscribe streams events until cancel is called. The channel is
+	// closed when the stream ends.
+	Subscribe() (events <-chan Event, cancel func(), err error)
+}
+
+// Runner owns the ticking of intervals and publishes their events to
+// subscribers. Ticking happens in the background, so the actions return
+// as soon as the repository is updated.
+type Runner struct {
+	config *IntervalConfig
+
+	ctx  context.Context
+	stop context.CancelFunc
+
+	mu         sync.Mutex
+	milestones []Milestone
+	// ticking is closed when the goroutine ticking the last started
+	// interval returns.
+	ticking chan struct{}
+
+	subMu  sync.Mutex
+	seq    uint64
+	subs   map[chan Event]struct{}
+	closed bool
+
+	idleMu    sync.Mutex
+	idle      idleState
+	idlePoll  time.Duration
+	reminders ReminderPolicy
+
+	errs chan error
+}
+
+func NewRunner(config *IntervalConfig) *Runner {
+	ctx, stop := context.WithCancel(context.Background())
+
+	r := &Runner{
+		config:   config,
+		ctx:      ctx,
+		stop:     stop,
+		subs:     map[chan Event]struct{}{},
+		errs:     make(chan error, 8),
+		idlePoll: defaultIdlePoll,
+	}
+
+	go r.watchIdle()
+
+	return r
+}
+
+// SetMilestones sets the milestones published while ticking, starting
+// with the next interval started.
+func (r *Runner) SetMilestones(ms []Milestone) error {
+	for _, m := range ms {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.milestones = ms
+
+	return nil
+}
+
+// Errors reports failures of the background ticking. Errors are dropped
+// when nobody reads them.
+func (r *Runner) Errors() <-chan error {
+	return r.errs
+}
+
+func (r *Runner) Status() (Interval, error) {
+	return Current(r.config)
+}
+
+// Start starts or resumes the current interval, or the next one if it
+// finished.
+func (r *Runner) Start() (Interval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := GetInterval(r.config)
+	if err != nil {
+		return i, err
+	}
+
+	return r.start(i)
+}
+
+// Resume starts the current interval only if it is paused.
+func (r *Runner) Resume() (Interval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := Current(r.config)
+	if err != nil {
+		return i, err
+	}
+
+	if i.State != StatePaused {
+		return i, fmt.Errorf("%w: %s is not paused", ErrInvalidState, i.Category)
+	}
+
+	return r.start(i)
+}
+
+func (r *Runner) Pause() (Interval, error) {
+	return r.act(EventPaused, current, func(i Interval) error {
+		return i.Pause(r.config)
+	})
+}
+
+func (r *Runner) End() (Interval, error) {
+	return r.act(EventDone, current, func(i Interval) error {
+		return i.End(r.config)
+	})
+}
+
+// Skip cancels the current interval and returns the next one. When the
+// current interval already finished, the one that would come next is
+// skipped without being stored.
+func (r *Runner) Skip() (Interval, error) {
+	r.mu.Lock()
+	i, err := Current(r.config)
+	if errors.Is(err, ErrNoInterval) || err == nil && (i.State == StateDone || i.State == StateCancelled) {
+		defer r.mu.Unlock()
+		return skipNext(r.config)
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		return Interval{}, err
+	}
+
+	if _, err := r.act(EventCancelled, GetInterval, func(i Interval) error {
+		return i.Skip(r.config)
+	}); err != nil {
+		return Interval{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return GetInterval(r.config)
+}
+
+// Extend adds d to the planned duration of the current interval.
+func (r *Runner) Extend(d time.Duration) (Interval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := Current(r.config)
+	if err != nil {
+		return i, err
+	}
+
+	if err := i.Extend(r.config, d); err != nil {
+		return i, err
+	}
+
+	return r.config.repo.ByID(i.ID)
+}
+
+func (r *Runner) Subscribe() (<-chan Event, func(), error) {
+	ch := make(chan Event, 64)
+
+	r.subMu.Lock()
+	if r.closed {
+		close(ch)
+	} else {
+		r.subs[ch] = struct{}{}
+	}
+	r.subMu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			r.subMu.Lock()
+			defer r.subMu.Unlock()
+
+			if _, ok := r.subs[ch]; ok {
+				delete(r.subs, ch)
+				close(ch)
+			}
+		})
+	}
+
+	return ch, cancel, nil
+}
+
+// Recover resumes ticking an interval left running by a process that went
+// away, such as a daemon that crashed. An interval is only taken over if
+// its progress does not move for two seconds, so one ticked elsewhere is
+// left alone.
+func (r *Runner) Recover() error {
+	i, err := Current(r.config)
+	if err == ErrNoInterval || (err == nil && i.State != StateRunning) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-time.After(2 * time.Second):
+	case <-r.ctx.Done():
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	j, err := r.config.repo.ByID(i.ID)
+	if err != nil {
+		return err
+	}
+
+	if j.State != StateRunning || j.ActualDuration != i.ActualDuration || r.isTicking() {
+		return nil
+	}
+
+	// Let Start see it as paused so that it ticks it again.
+	j.State = StatePaused
+	if err := r.config.repo.Update(j); err != nil {
+		return err
+	}
+
+	_, err = r.start(j)
+
+	return err
+}
+
+// Close pauses the interval this runner is ticking, so that it can be
+// resumed later, and ends all subscriptions.
+func (r *Runner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	if r.isTicking() {
+		var i Interval
+		if i, err = Current(r.config); err == nil && i.State == StateRunning {
+			err = i.Pause(r.config)
+		}
+	}
+
+	r.stop()
+	if r.ticking != nil {
+		<-r.ticking
+	}
+
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+
+	for ch := range r.subs {
+		close(ch)
+	}
+	r.subs = nil
+	r.closed = true
+
+	return err
+}
+
+// start ticks i in the background and returns it once it is running. The
+// caller holds r.mu.
+func (r *Runner) start(i Interval) (Interval, error) {
+	// Ticked by this runner or by another process.
+	if i.State == StateRunning {
+		return i, nil
+	}
+
+	// The previous interval may still be finishing its last tick.
+	if r.ticking != nil {
+		<-r.ticking
+	}
+
+	started := make(chan Interval, 1)
+	errc := make(chan error, 1)
+	done := make(chan struct{})
+	r.ticking = done
+	milestones := r.milestones
+
+	go func() {
+		defer close(done)
+
+		running := false
+		// Milestones are reached between two ticks, so resuming does not
+		// publish them again.
+		var last time.Duration
+		err := i.Start(r.ctx, r.config,
+			func(i Interval) {
+				running = true
+				last = i.ActualDuration
+
+				r.idleMu.Lock()
+				if err := r.endIdle(time.Now()); err != nil {
+					r.reportError(err)
+				}
+				r.idleMu.Unlock()
+
+				r.publish(EventStarted, i)
+				started <- i
+			},
+			func(i Interval) {
+				r.publish(EventTick, i)
+
+				for _, m := range reached(milestones, last, i) {
+					r.publishEvent(Event{Type: EventMilestone, Interval: i, Milestone: &m})
+				}
+				last = i.ActualDuration
+			},
+			func(Interval) {},
+		)
+
+		switch {
+		case err != nil && !running:
+			errc <- err
+			return
+		case err != nil:
+			r.reportError(err)
+			return
+		}
+
+		r.finish(i.ID)
+	}()
+
+	select {
+	case i := <-started:
+		return i, nil
+	case err := <-errc:
+		return i, err
+	}
+}
+
+// finish publishes how the interval ticked by this runner ended.
+func (r *Runner) finish(id int64) {
+	i, err := r.config.repo.ByID(id)
+	if err != nil {
+		r.reportError(err)
+		return
+	}
+
+	if i.State == StateDone || i.State == StateCancelled {
+		r.idleMu.Lock()
+		r.beginIdle(time.Now(), true)
+		r.idleMu.Unlock()
+	}
+
+	switch i.State {
+	case StatePaused:
+		r.publish(EventPaused, i)
+	case StateDone:
+		r.publish(EventDone, i)
+	case StateCancelled:
+		r.publish(EventCancelled, i)
+	}
+}
+
+// act applies action to the interval returned by get. When this runner is
+// ticking it, the event is published once the ticking stops; otherwise it
+// is published right away.
+func (r *Runner) act(event string, get func(*IntervalConfig) (Interval, error),
+	action func(Interval) error,
+) (Interval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, err := get(r.config)
+	if err != nil {
+		return i, err
+	}
+
+	if err := action(i); err != nil {
+		return i, err
+	}
+
+	if i, err = r.config.repo.ByID(i.ID); err != nil {
+		return i, err
+	}
+
+	if !r.isTicking() {
+		r.publish(event, i)
+	}
+
+	return i, nil
+}
+
+// current returns the last interval without creating the next one the way
+// GetInterval does, so that acting on a finished interval stores nothing.
+func current(config *IntervalConfig) (Interval, error) {
+	i, err := Current(config)
+	if errors.Is(err, ErrNoInterval) {
+		return i, ErrIntervalNotRunning
+	}
+
+	return i, err
+}
+
+// isTicking reports whether the ticking goroutine is still running. The
+// caller holds r.mu.
+func (r *Runner) isTicking() bool {
+	if r.ticking == nil {
+		return false
+	}
+
+	select {
+	case <-r.ticking:
+		return false
+	default:
+		return true
+	}
+}
+
+// publish sends an event to every subscriber. Subscribers that fall behind
+// miss events rather than stall the timer.
+func (r *Runner) publish(typ string, i Interval) {
+	r.publishEvent(Event{Type: typ, Interval: i})
+}
+
+// publishEvent numbers and timestamps e before publishing it.
+func (r *Runner) publishEvent(e Event) {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+
+	r.seq++
+	e.Seq = r.seq
+	e.Time = time.Now()
+
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+func (r *Runner) reportError(err error) {
+	select {
+	case r.errs <- err:
+	default:
+	}
+}
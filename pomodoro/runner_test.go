@@ -0,0 +1,320 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+// nextEvent returns the next event that is not a tick.
+func nextEvent(t *testing.T, events <-chan pomodoro.Event) pomodoro.Event {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("Event stream closed")
+			}
+			if e.Type != pomodoro.EventTick {
+				return e
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for an event")
+		}
+	}
+}
+
+func TestRunner(t *testing.T) {
+	const duration = 2 * time.Second
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	r := pomodoro.NewRunner(config)
+
+	events, cancel, err := r.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	expect := func(typ string, state int) pomodoro.Event {
+		t.Helper()
+
+		e := nextEvent(t, events)
+		if e.Type != typ || e.Interval.State != state {
+			t.Fatalf("Expected %s event with state %d, got %s with %d instead.\n",
+				typ, state, e.Type, e.Interval.State)
+		}
+
+		return e
+	}
+
+	if _, err := r.Pause(); !errors.Is(err, pomodoro.ErrIntervalNotRunning) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrIntervalNotRunning, err)
+	}
+
+	if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoInterval) {
+		t.Errorf("Expected no interval after a failed pause, got %v instead.\n", err)
+	}
+
+	i, err := r.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i.State != pomodoro.StateRunning {
+		t.Errorf("Expected state %d, got %d instead.\n", pomodoro.StateRunning, i.State)
+	}
+
+	first := expect(pomodoro.EventStarted, pomodoro.StateRunning)
+
+	if i, err = r.Extend(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if i.PlannedDuration != duration+time.Second {
+		t.Errorf("Expected planned duration %q, got %q instead.\n",
+			duration+time.Second, i.PlannedDuration)
+	}
+
+	if _, err := r.Extend(-time.Second); !errors.Is(err, pomodoro.ErrInvalidDuration) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrInvalidDuration, err)
+	}
+
+	if _, err := r.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	expect(pomodoro.EventPaused, pomodoro.StatePaused)
+
+	if _, err := r.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	expect(pomodoro.EventStarted, pomodoro.StateRunning)
+
+	done := expect(pomodoro.EventDone, pomodoro.StateDone)
+	if done.Interval.ID != i.ID || done.Interval.ActualDuration != duration+time.Second {
+		t.Errorf("Expected interval %d done after %q, got %+v instead.\n",
+			i.ID, duration+time.Second, done.Interval)
+	}
+
+	if done.Seq <= first.Seq {
+		t.Errorf("Expected increasing sequence numbers, got %d after %d.\n",
+			done.Seq, first.Seq)
+	}
+
+	if _, err := r.Resume(); !errors.Is(err, pomodoro.ErrInvalidState) {
+		t.Errorf("Expected error %q, got %v instead.\n", pomodoro.ErrInvalidState, err)
+	}
+
+	for name, act := range map[string]func() (pomodoro.Interval, error){"Pause": r.Pause, "End": r.End} {
+		if _, err := act(); !errors.Is(err, pomodoro.ErrIntervalNotRunning) {
+			t.Errorf("%s: expected error %q, got %v instead.\n", name, pomodoro.ErrIntervalNotRunning, err)
+		}
+
+		if last, err := repo.Last(); err != nil || last.ID != i.ID {
+			t.Errorf("%s: expected interval %d to stay the last, got %+v instead.\n", name, i.ID, last)
+		}
+	}
+
+	// Nothing is in progress, so the break is skipped without storing it.
+	next, err := r.Skip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.Category != pomodoro.CategoryPomodoro || next.State != pomodoro.StateNotStarted {
+		t.Errorf("Expected a new %s after skipping the break, got %+v instead.\n",
+			pomodoro.CategoryPomodoro, next)
+	}
+
+	if next.ID != i.ID+1 {
+		t.Errorf("Expected interval %d after %d, got %d instead.\n", i.ID+1, i.ID, next.ID)
+	}
+
+	if breaks, err := repo.Breaks(1); err != nil || len(breaks) != 0 {
+		t.Errorf("Expected no skipped break stored, got %+v, %v.\n", breaks, err)
+	}
+
+	if _, err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+	expect(pomodoro.EventStarted, pomodoro.StateRunning)
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = r.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i.State != pomodoro.StatePaused {
+		t.Errorf("Expected Close to pause the interval, got state %d instead.\n", i.State)
+	}
+}
+
+func TestRunnerMilestones(t *testing.T) {
+	const duration = 4 * time.Second
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	r := pomodoro.NewRunner(config)
+	defer r.Close()
+
+	err := r.SetMilestones([]pomodoro.Milestone{
+		{Category: pomodoro.CategoryPomodoro, Before: time.Second, Message: "1s left"},
+		{Progress: 0.5, Message: "Halfway"},
+		{Category: pomodoro.CategoryShortBreak, Before: time.Second, Message: "Break ends soon"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, cancel, err := r.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	if _, err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for {
+		e := nextEvent(t, events)
+		if e.Type == pomodoro.EventDone {
+			break
+		}
+
+		if e.Type == pomodoro.EventMilestone {
+			got = append(got, fmt.Sprintf("%s at %s", e.Milestone.Message, e.Interval.ActualDuration))
+		}
+	}
+
+	exp := []string{"Halfway at 2s", "1s left at 3s"}
+	if strings.Join(got, ", ") != strings.Join(exp, ", ") {
+		t.Errorf("Expected milestones %q, got %q instead.\n", exp, got)
+	}
+}
+
+func TestRunnerIdle(t *testing.T) {
+	const (
+		duration = 100 * time.Millisecond
+		every    = 200 * time.Millisecond
+	)
+
+	defer pomodoro.SetIdlePoll(10 * time.Millisecond)()
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	r := pomodoro.NewRunner(config)
+	defer r.Close()
+
+	if err := r.SetReminders(pomodoro.ReminderPolicy{Every: every}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, cancel, err := r.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	if _, err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	for nextEvent(t, events).Type != pomodoro.EventDone {
+	}
+
+	var since time.Time
+	for n := 1; n <= 2; n++ {
+		e := nextEvent(t, events)
+		if e.Type != pomodoro.EventIdle || e.Idle == nil || e.Idle.Reminders != n {
+			t.Fatalf("Expected idle reminder %d, got %+v instead.\n", n, e)
+		}
+
+		if n > 1 && !e.Idle.Since.Equal(since) {
+			t.Errorf("Expected idle since %s, got %s instead.\n", since, e.Idle.Since)
+		}
+		since = e.Idle.Since
+	}
+
+	if _, err := r.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The gap is recorded when the interval starts, not when a poll sees
+	// it running.
+	idle, err := pomodoro.IdleSummary(time.Now(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idle < 2*every {
+		t.Errorf("Expected at least %s idle, got %s instead.\n", 2*every, idle)
+	}
+}
+
+func TestRunnerIdleBetweenPolls(t *testing.T) {
+	const duration = 100 * time.Millisecond
+
+	// The intervals start and finish between two polls, so only the
+	// runner sees them.
+	defer pomodoro.SetIdlePoll(200 * time.Millisecond)()
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+	r := pomodoro.NewRunner(config)
+	defer r.Close()
+
+	events, cancel, err := r.Subscribe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	for n := 0; n < 2; n++ {
+		if _, err := r.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		for nextEvent(t, events).Type != pomodoro.EventDone {
+		}
+
+		if n == 0 {
+			time.Sleep(300 * time.Millisecond)
+		}
+	}
+
+	gr := repo.(pomodoro.GapRecorder)
+
+	gaps, err := gr.Gaps(time.Now().Add(-time.Minute), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gaps) != 1 {
+		t.Fatalf("Expected 1 gap, got %d instead.\n", len(gaps))
+	}
+	if gaps[0].Duration() < 300*time.Millisecond {
+		t.Errorf("Expected a gap of at least 300ms, got %s instead.\n", gaps[0].Duration())
+	}
+}
@@ -0,0 +1,238 @@
+// Package sound plays short audio cues on timer events with whichever
+// player the system has, falling back to the terminal bell.
+package sound
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	CueStart     = "start"
+	CueEnd       = "end"
+	CueBreakOver = "break_over"
+	CueWarning   = "warning"
+)
+
+// Cues lists the cues that can be configured.
+var Cues = []string{CueStart, CueEnd, CueBreakOver, CueWarning}
+
+// Values of Config.Files besides a path.
+const (
+	FileDefault = "default"
+	FileNone    = "none"
+)
+
+var (
+	ErrUnknownCue    = errors.New("Unknown sound cue")
+	ErrUnknownPlayer = errors.New("Unknown sound player")
+	ErrInvalidVolume = errors.New("Invalid volume")
+)
+
+//go:embed cues
+var embedded embed.FS
+
+// These are replaced by tests.
+var (
+	command  = exec.Command
+	lookPath = exec.LookPath
+	openTTY  = func() (io.WriteCloser, error) {
+		return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	}
+)
+
+type player struct {
+	args func(file string, volume int) []string
+	// wavOnly players cannot decode other formats.
+	wavOnly bool
+}
+
+var players = map[string]player{
+	"paplay": {args: func(file string, volume int) []string {
+		return []string{"--volume=" + strconv.Itoa(volume*65536/100), file}
+	}},
+	"pw-play": {args: func(file string, volume int) []string {
+		return []string{"--volume=" + strconv.FormatFloat(float64(volume)/100, 'f', 2, 64), file}
+	}},
+	"aplay": {wavOnly: true, args: func(file string, volume int) []string {
+		return []string{"-q", file}
+	}},
+	"ffplay": {args: func(file string, volume int) []string {
+		return []string{"-nodisp", "-autoexit", "-loglevel", "quiet", "-volume", strconv.Itoa(volume), file}
+	}},
+}
+
+// DefaultPlayers are tried in this order.
+var DefaultPlayers = []string{"paplay", "pw-play", "aplay", "ffplay"}
+
+type Config struct {
+	// Files maps cues to audio files. Cues left out or set to FileDefault
+	// play the embedded sound, and FileNone plays nothing.
+	Files map[string]string
+	// Volume is in percent, from 0 (silent) to 100.
+	Volume int
+	// Players overrides DefaultPlayers.
+	Players []string
+}
+
+// Player plays the cues of a Config.
+type Player struct {
+	config Config
+
+	mu sync.Mutex
+	// dir holds the embedded cues once a player needs them as files.
+	dir string
+}
+
+func New(config Config) (*Player, error) {
+	for cue := range config.Files {
+		if !slices.Contains(Cues, cue) {
+			return nil, fmt.Errorf("%w: %q, expected one of %s",
+				ErrUnknownCue, cue, strings.Join(Cues, ", "))
+		}
+	}
+
+	if config.Volume < 0 || config.Volume > 100 {
+		return nil, fmt.Errorf("%w: %d, expected 0 to 100", ErrInvalidVolume, config.Volume)
+	}
+
+	if len(config.Players) == 0 {
+		config.Players = DefaultPlayers
+	}
+
+	for _, name := range config.Players {
+		if _, ok := players[name]; !ok {
+			return nil, fmt.Errorf("%w: %q, expected one of %s",
+				ErrUnknownPlayer, name, strings.Join(DefaultPlayers, ", "))
+		}
+	}
+
+	return &Player{config: config}, nil
+}
+
+// Play plays cue and returns once it finished. The installed players are
+// tried in order; when none is installed, or all of them fail, the
+// terminal bell rings instead. Failures of the players are returned even
+// when the bell rang.
+func (p *Player) Play(cue string) error {
+	if !slices.Contains(Cues, cue) {
+		return fmt.Errorf("%w: %q", ErrUnknownCue, cue)
+	}
+
+	if p.config.Volume == 0 || p.config.Files[cue] == FileNone {
+		return nil
+	}
+
+	file, err := p.file(cue)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, name := range p.config.Players {
+		pl := players[name]
+		if pl.wavOnly && filepath.Ext(file) != ".wav" {
+			continue
+		}
+
+		exe, err := lookPath(name)
+		if err != nil {
+			continue
+		}
+
+		out, err := command(exe, pl.args(file, p.config.Volume)...).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	if err := bell(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close removes the embedded cues written to disk.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.dir == "" {
+		return nil
+	}
+
+	err := os.RemoveAll(p.dir)
+	p.dir = ""
+
+	return err
+}
+
+// file returns the path of the audio file of cue, writing the embedded
+// one to disk if needed.
+func (p *Player) file(cue string) (string, error) {
+	if f := p.config.Files[cue]; f != "" && f != FileDefault {
+		if _, err := os.Stat(f); err != nil {
+			return "", fmt.Errorf("%s: %w", cue, err)
+		}
+		return f, nil
+	}
+
+	matches, err := fs.Glob(embedded, "cues/"+cue+".*")
+	if err != nil || len(matches) == 0 {
+		return "", fmt.Errorf("%w: no embedded sound for %q", ErrUnknownCue, cue)
+	}
+	name := matches[0]
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.dir == "" {
+		dir, err := os.MkdirTemp("", "ztimer-sounds-")
+		if err != nil {
+			return "", err
+		}
+		p.dir = dir
+	}
+
+	file := filepath.Join(p.dir, path.Base(name))
+	if _, err := os.Stat(file); err == nil {
+		return file, nil
+	}
+
+	data, err := embedded.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+
+	return file, os.WriteFile(file, data, 0o600)
+}
+
+// bell rings the bell of the controlling terminal.
+func bell() error {
+	tty, err := openTTY()
+	if err != nil {
+		return fmt.Errorf("bell: %w", err)
+	}
+	defer tty.Close()
+
+	_, err = io.WriteString(tty, "\a")
+
+	return err
+}
@@ -0,0 +1,200 @@
+package sound
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// mockPlayers makes only the installed players available. The ones in
+// failing exit with an error. It returns the command lines run and the
+// bytes written to the terminal.
+func mockPlayers(t *testing.T, installed, failing []string) (*[]string, *bytes.Buffer) {
+	t.Helper()
+
+	origCommand, origLookPath, origOpenTTY := command, lookPath, openTTY
+	t.Cleanup(func() { command, lookPath, openTTY = origCommand, origLookPath, origOpenTTY })
+
+	var calls []string
+	var tty bytes.Buffer
+
+	lookPath = func(name string) (string, error) {
+		if slices.Contains(installed, name) {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+
+	command = func(exe string, args ...string) *exec.Cmd {
+		name := filepath.Base(exe)
+		calls = append(calls, name+" "+strings.Join(args, " "))
+
+		cmd := exec.Command(os.Args[0], "-test.run=TestHelperProcess")
+		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
+		if slices.Contains(failing, name) {
+			cmd.Env = append(cmd.Env, "HELPER_FAIL=1")
+		}
+
+		return cmd
+	}
+
+	openTTY = func() (io.WriteCloser, error) { return nopCloser{&tty}, nil }
+
+	return &calls, &tty
+}
+
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	if os.Getenv("HELPER_FAIL") == "1" {
+		os.Stderr.WriteString("device busy")
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+func TestPlay(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cue       string
+		files     map[string]string
+		volume    int
+		installed []string
+		failing   []string
+		expCalls  []string
+		expBell   bool
+		expErr    string
+	}{
+		{name: "FirstPlayer", cue: CueStart, volume: 50,
+			installed: []string{"pw-play", "paplay"},
+			expCalls:  []string{"paplay --volume=32768 start.wav"}},
+		{name: "PipeWire", cue: CueStart, volume: 80,
+			installed: []string{"pw-play", "ffplay"},
+			expCalls:  []string{"pw-play --volume=0.80 start.wav"}},
+		{name: "NextOnFailure", cue: CueEnd, volume: 100,
+			installed: []string{"paplay", "ffplay"}, failing: []string{"paplay"},
+			expCalls: []string{
+				"paplay --volume=65536 end.oga",
+				"ffplay -nodisp -autoexit -loglevel quiet -volume 100 end.oga",
+			}},
+		{name: "WavOnly", cue: CueEnd, volume: 100,
+			installed: []string{"aplay"}, expBell: true},
+		{name: "Aplay", cue: CueWarning, volume: 100,
+			installed: []string{"aplay"},
+			expCalls:  []string{"aplay -q warning.wav"}},
+		{name: "NoPlayer", cue: CueBreakOver, volume: 100, expBell: true},
+		{name: "AllFail", cue: CueStart, volume: 100,
+			installed: []string{"aplay"}, failing: []string{"aplay"},
+			expCalls: []string{"aplay -q start.wav"},
+			expBell:  true, expErr: "aplay: exit status 1: device busy"},
+		{name: "None", cue: CueStart, volume: 100, files: map[string]string{CueStart: FileNone},
+			installed: []string{"paplay"}},
+		{name: "Silent", cue: CueStart, volume: 0, installed: []string{"paplay"}},
+		{name: "Missing", cue: CueStart, volume: 100, files: map[string]string{CueStart: "/nonexistent.wav"},
+			installed: []string{"paplay"}, expErr: "no such file"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls, tty := mockPlayers(t, tc.installed, tc.failing)
+
+			p, err := New(Config{Files: tc.files, Volume: tc.volume})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.Close()
+
+			err = p.Play(tc.cue)
+			if tc.expErr == "" && err != nil {
+				t.Fatalf("Expected no error, got %q instead\n", err)
+			}
+			if tc.expErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expErr)) {
+				t.Errorf("Expected error containing %q, got %v instead\n", tc.expErr, err)
+			}
+
+			// Only the file names, the directory is temporary.
+			var got []string
+			for _, c := range *calls {
+				fields := strings.Fields(c)
+				fields[len(fields)-1] = filepath.Base(fields[len(fields)-1])
+				got = append(got, strings.Join(fields, " "))
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tc.expCalls, "\n") {
+				t.Errorf("Expected calls %q, got %q instead\n", tc.expCalls, got)
+			}
+
+			if rang := tty.String() == "\a"; rang != tc.expBell {
+				t.Errorf("Expected bell %t, got %q written instead\n", tc.expBell, tty.String())
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		expErr error
+	}{
+		{name: "Defaults", config: Config{Volume: 100}},
+		{name: "UnknownCue", config: Config{Files: map[string]string{"tick": "a.wav"}}, expErr: ErrUnknownCue},
+		{name: "Volume", config: Config{Volume: 150}, expErr: ErrInvalidVolume},
+		{name: "Player", config: Config{Players: []string{"mpv"}}, expErr: ErrUnknownPlayer},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.config)
+
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("Expected error %v, got %v instead\n", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	p, err := New(Config{Volume: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dir string
+	for _, cue := range Cues {
+		file, err := p.file(cue)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir = filepath.Dir(file)
+
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() == 0 {
+			t.Errorf("Expected the %s cue to have sound, got an empty file instead\n", cue)
+		}
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v instead\n", dir, err)
+	}
+}
@@ -1,31 +1,16 @@
-//go:build !inmemory
-// +build !inmemory
+//go:build !inmemory && !ndjson
+// +build !inmemory,!ndjson
 
 package pomodoro_test
 
 import (
-	"os"
 	"testing"
 
 	"github.com/ZeroBl21/go-ztimer/pomodoro"
-	"github.com/ZeroBl21/go-ztimer/pomodoro/repository"
 )
 
 func getRepo(t *testing.T) (pomodoro.Repository, func()) {
 	t.Helper()
 
-	tf, err := os.CreateTemp("", "pomo")
-	if err != nil {
-		t.Fatal(err)
-	}
-	tf.Close()
-
-	dbRepo, err := repository.NewSQLiteRepo(tf.Name())
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	return dbRepo, func() {
-		os.Remove(tf.Name())
-	}
+	return openRepo(t, "sqlite")
 }
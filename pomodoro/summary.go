@@ -28,6 +28,12 @@ func RangeSummary(
 		Values: make([]float64, nDays),
 	}
 
+	idleSeries := LineSeries{
+		Name:   "Idle",
+		Labels: map[int]string{},
+		Values: make([]float64, nDays),
+	}
+
 	for i := range nDays {
 		day := start.AddDate(0, 0, -i)
 		ds, err := DailySummary(day, config)
@@ -42,14 +48,20 @@ func RangeSummary(
 
 		breakSeries.Labels[i] = label
 		breakSeries.Values[i] = ds[1].Seconds()
+
+		idleSeries.Labels[i] = label
+		idleSeries.Values[i] = ds[2].Seconds()
 	}
 
 	return []LineSeries{
 		pomodoroSeries,
 		breakSeries,
+		idleSeries,
 	}, nil
 }
 
+// DailySummary returns the time spent in pomodoros, in breaks and idle
+// between intervals on the local day of day.
 func DailySummary(
 	day time.Time,
 	config *IntervalConfig,
@@ -64,8 +76,14 @@ func DailySummary(
 		return nil, err
 	}
 
+	dIdle, err := IdleSummary(day, config)
+	if err != nil {
+		return nil, err
+	}
+
 	return []time.Duration{
 		dPomo,
 		dBreaks,
+		dIdle,
 	}, nil
 }
@@ -0,0 +1,110 @@
+package pomodoro
+
+import (
+	"context"
+	"time"
+)
+
+// watchPoll is how often WatchEvents reads the repository. It is well below
+// a second so that no tick is missed.
+const watchPoll = 250 * time.Millisecond
+
+// WatchEvents derives events from the repository for intervals ticked by
+// other processes, such as the TUI or a foreground start, until ctx is done.
+// Events already under way when it is called are not replayed. Both channels
+// are closed when it stops; an error reading the repository is sent on the
+// buffered errs before events is closed.
+func WatchEvents(ctx context.Context, config *IntervalConfig) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			close(events)
+			close(errs)
+		}()
+
+		ticker := time.NewTicker(watchPoll)
+		defer ticker.Stop()
+
+		var (
+			seq  uint64
+			prev Interval
+		)
+
+		emit := func(typ string, i Interval) bool {
+			seq++
+
+			select {
+			case events <- Event{Seq: seq, Type: typ, Time: time.Now(), Interval: i}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		first := true
+
+		for {
+			cur, err := Current(config)
+			if err != nil && err != ErrNoInterval {
+				errs <- err
+				return
+			}
+
+			if !first {
+				for _, e := range intervalEvents(config, prev, cur) {
+					if !emit(e.Type, e.Interval) {
+						return
+					}
+				}
+			}
+			first, prev = false, cur
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// intervalEvents returns the events leading from the interval seen last,
+// prev, to the current one. When a new interval took over, the last state
+// of prev is reported first.
+func intervalEvents(config *IntervalConfig, prev, cur Interval) []Event {
+	var events []Event
+
+	if prev.ID != 0 && cur.ID != prev.ID {
+		if last, err := config.repo.ByID(prev.ID); err == nil {
+			events = append(events, intervalEvents(config, prev, last)...)
+		}
+	}
+
+	if cur.ID == 0 {
+		return events
+	}
+
+	if cur.ID == prev.ID && cur.State == prev.State {
+		if cur.State == StateRunning && cur.ActualDuration != prev.ActualDuration {
+			events = append(events, Event{Type: EventTick, Interval: cur})
+		}
+		return events
+	}
+
+	switch cur.State {
+	case StateRunning:
+		events = append(events, Event{Type: EventStarted, Interval: cur})
+	case StatePaused:
+		events = append(events, Event{Type: EventPaused, Interval: cur})
+	case StateDone:
+		events = append(events, Event{Type: EventDone, Interval: cur})
+	case StateCancelled:
+		events = append(events, Event{Type: EventCancelled, Interval: cur})
+	}
+
+	return events
+}
@@ -0,0 +1,109 @@
+package pomodoro_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+func TestWatchEvents(t *testing.T) {
+	const duration = 2 * time.Second
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, duration, duration, duration)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, errs := pomodoro.WatchEvents(ctx, config)
+
+	// Let the watcher take its first look before anything happens.
+	time.Sleep(100 * time.Millisecond)
+
+	i, err := pomodoro.GetInterval(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(pomodoro.Interval) {}
+	go i.Start(ctx, config, noop, noop, noop)
+
+	var (
+		types []string
+		seq   uint64
+	)
+
+	timeout := time.After(5 * time.Second)
+
+	for len(types) == 0 || types[len(types)-1] != pomodoro.EventDone {
+		select {
+		case e := <-events:
+			if e.Seq != seq+1 {
+				t.Errorf("Expected sequence number %d, got %d instead.\n", seq+1, e.Seq)
+			}
+			seq = e.Seq
+
+			if e.Interval.ID != i.ID {
+				t.Fatalf("Expected events for interval %d, got %+v", i.ID, e)
+			}
+			types = append(types, e.Type)
+
+		case err := <-errs:
+			t.Fatal(err)
+
+		case <-timeout:
+			t.Fatalf("Timed out waiting for the interval to finish, got %v", types)
+		}
+	}
+
+	if types[0] != pomodoro.EventStarted || len(types) < 3 {
+		t.Fatalf("Expected started, ticks and done, got %v instead.\n", types)
+	}
+
+	for _, typ := range types[1 : len(types)-1] {
+		if typ != pomodoro.EventTick {
+			t.Errorf("Expected only ticks between started and done, got %v.\n", types)
+		}
+	}
+}
+
+// failingRepo fails to read the last interval.
+type failingRepo struct {
+	pomodoro.Repository
+}
+
+var errRead = errors.New("read failed")
+
+func (failingRepo) Last() (pomodoro.Interval, error) {
+	return pomodoro.Interval{}, errRead
+}
+
+func TestWatchEventsError(t *testing.T) {
+	config := pomodoro.NewConfig(failingRepo{}, 0, 0, 0)
+
+	events, errs := pomodoro.WatchEvents(context.Background(), config)
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("Expected no events from a failing repository")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the events to close")
+	}
+
+	// The error is already waiting once the events are closed.
+	select {
+	case err := <-errs:
+		if !errors.Is(err, errRead) {
+			t.Errorf("Expected error %q, got %v instead.\n", errRead, err)
+		}
+	default:
+		t.Error("Expected the error before the events closed")
+	}
+}
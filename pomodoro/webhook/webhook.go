@@ -0,0 +1,417 @@
+// Package webhook posts timer events to HTTP endpoints, such as a team
+// dashboard.
+//
+// Every delivery is a POST of the pomodoro.Event as JSON with the headers
+//
+//	X-Ztimer-Event:     done
+//	X-Ztimer-Delivery:  9f86d081884c7d65...
+//	X-Ztimer-Signature: sha256=<hex HMAC-SHA256 of the body>
+//
+// where the signature is only sent for targets with a secret. Deliveries
+// that fail are written to a queue directory and retried with exponential
+// backoff, by this process or the next one using the same queue.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+)
+
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultBackoff     = 30 * time.Second
+	DefaultMaxBackoff  = time.Hour
+	DefaultMaxAttempts = 10
+)
+
+// retryPoll is how often the queue is checked for deliveries due again.
+const retryPoll = 5 * time.Second
+
+// A claimed delivery left behind by a process that died is taken over
+// after claimTimeout.
+const claimTimeout = 10 * time.Minute
+
+var (
+	ErrNoURL        = errors.New("Webhook without URL")
+	ErrInvalidEvent = errors.New("Invalid webhook event")
+)
+
+// DefaultEvents are sent to targets that do not pick their own.
+var DefaultEvents = []string{
+	pomodoro.EventStarted,
+	pomodoro.EventDone,
+	pomodoro.EventCancelled,
+}
+
+// validEvents are the events a target can pick. Ticks are too frequent to
+// be worth a request.
+var validEvents = []string{
+	pomodoro.EventStarted,
+	pomodoro.EventPaused,
+	pomodoro.EventDone,
+	pomodoro.EventCancelled,
+	pomodoro.EventMilestone,
+	pomodoro.EventIdle,
+}
+
+type Target struct {
+	URL string `mapstructure:"url"`
+	// Secret signs the body when set.
+	Secret string `mapstructure:"secret"`
+	// Events defaults to DefaultEvents.
+	Events []string `mapstructure:"events"`
+}
+
+type Config struct {
+	Targets []Target
+	// QueueDir keeps the deliveries that failed. Without it they are
+	// dropped after the first attempt.
+	QueueDir string
+	// Zero values use the defaults above.
+	Timeout     time.Duration
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	MaxAttempts int
+}
+
+// delivery is a queued request.
+type delivery struct {
+	ID       string          `json:"id"`
+	URL      string          `json:"url"`
+	Event    string          `json:"event"`
+	Body     json.RawMessage `json:"body"`
+	Attempts int             `json:"attempts"`
+	Next     time.Time       `json:"next_attempt"`
+	LastErr  string          `json:"last_error,omitempty"`
+}
+
+// Webhooks delivers events to the targets of a Config. Failures are written
+// to its logger.
+type Webhooks struct {
+	config Config
+	client *http.Client
+	log    *log.Logger
+
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+func New(config Config, logOut io.Writer) (*Webhooks, error) {
+	for i, t := range config.Targets {
+		if t.URL == "" {
+			return nil, ErrNoURL
+		}
+
+		if len(t.Events) == 0 {
+			config.Targets[i].Events = DefaultEvents
+		}
+
+		for _, e := range t.Events {
+			if !slices.Contains(validEvents, e) {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidEvent, e)
+			}
+		}
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if config.QueueDir != "" {
+		if err := os.MkdirAll(config.QueueDir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+
+	return &Webhooks{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		log:    log.New(logOut, "webhook: ", log.LstdFlags),
+		ctx:    ctx,
+		stop:   stop,
+	}, nil
+}
+
+// Follow delivers the events of ctl until the subscription ends, and
+// retries the queued deliveries in the meantime.
+func (w *Webhooks) Follow(ctl pomodoro.Controller) error {
+	events, _, err := ctl.Subscribe()
+	if err != nil {
+		return err
+	}
+
+	w.wg.Add(2)
+	go func() {
+		defer w.wg.Done()
+
+		for e := range events {
+			w.Dispatch(e)
+		}
+	}()
+
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(retryPoll)
+		defer ticker.Stop()
+
+		for {
+			w.Retry()
+
+			select {
+			case <-ticker.C:
+			case <-w.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Dispatch sends e to the targets that want it without waiting for the
+// responses.
+func (w *Webhooks) Dispatch(e pomodoro.Event) {
+	body, err := json.Marshal(pomodoro.NewEventJSON(e))
+	if err != nil {
+		w.log.Printf("%s: %v", e.Type, err)
+		return
+	}
+
+	for _, t := range w.config.Targets {
+		if !slices.Contains(t.Events, e.Type) {
+			continue
+		}
+
+		d := delivery{ID: newID(), URL: t.URL, Event: e.Type, Body: body}
+
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+
+			w.attempt(d)
+		}()
+	}
+}
+
+// Retry sends the queued deliveries whose backoff has passed.
+func (w *Webhooks) Retry() {
+	if w.config.QueueDir == "" {
+		return
+	}
+
+	entries, err := os.ReadDir(w.config.QueueDir)
+	if err != nil {
+		w.log.Print(err)
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// Claims of processes that went away.
+		if strings.HasSuffix(name, ".sending") {
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > claimTimeout {
+				os.Rename(w.path(name), w.path(strings.TrimSuffix(name, ".sending")))
+			}
+			continue
+		}
+
+		if filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		d, err := w.claim(name)
+		if err != nil {
+			// Another process got it first.
+			if !errors.Is(err, os.ErrNotExist) {
+				w.log.Print(err)
+			}
+			continue
+		}
+
+		if time.Now().Before(d.Next) {
+			w.release(d)
+			continue
+		}
+
+		w.attempt(d)
+	}
+}
+
+// Close stops retrying and waits for the deliveries in flight.
+func (w *Webhooks) Close() {
+	w.stop()
+	w.wg.Wait()
+}
+
+// attempt sends d and queues it again if it fails.
+func (w *Webhooks) attempt(d delivery) {
+	err := w.send(d)
+	if err == nil {
+		w.remove(d)
+		return
+	}
+
+	d.Attempts++
+	d.LastErr = err.Error()
+
+	if w.config.QueueDir == "" || d.Attempts >= w.config.MaxAttempts {
+		w.log.Printf("%s to %s: giving up after %d attempts: %v", d.Event, d.URL, d.Attempts, err)
+		w.remove(d)
+		return
+	}
+
+	backoff := w.config.Backoff << (d.Attempts - 1)
+	if backoff > w.config.MaxBackoff || backoff <= 0 {
+		backoff = w.config.MaxBackoff
+	}
+	d.Next = time.Now().Add(backoff)
+
+	w.log.Printf("%s to %s: %v, retrying in %s", d.Event, d.URL, err, backoff)
+	w.release(d)
+}
+
+func (w *Webhooks) send(d delivery) error {
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-ztimer")
+	req.Header.Set("X-Ztimer-Event", d.Event)
+	req.Header.Set("X-Ztimer-Delivery", d.ID)
+
+	if secret := w.secret(d.URL); secret != "" {
+		req.Header.Set("X-Ztimer-Signature", Sign(secret, d.Body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// secret looks the secret up by URL, so that it is never written to the
+// queue.
+func (w *Webhooks) secret(url string) string {
+	for _, t := range w.config.Targets {
+		if t.URL == url {
+			return t.Secret
+		}
+	}
+
+	return ""
+}
+
+// claim takes the queued delivery in name for this process.
+func (w *Webhooks) claim(name string) (delivery, error) {
+	var d delivery
+
+	claimed := w.path(name + ".sending")
+	if err := os.Rename(w.path(name), claimed); err != nil {
+		return d, err
+	}
+
+	data, err := os.ReadFile(claimed)
+	if err != nil {
+		return d, err
+	}
+
+	if err := json.Unmarshal(data, &d); err != nil {
+		os.Remove(claimed)
+		return d, fmt.Errorf("dropping %s: %w", name, err)
+	}
+
+	return d, nil
+}
+
+// release writes d back to the queue.
+func (w *Webhooks) release(d delivery) {
+	if w.config.QueueDir == "" {
+		return
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		w.log.Print(err)
+		return
+	}
+
+	tmp := w.path(d.ID + ".tmp")
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		w.log.Print(err)
+		return
+	}
+
+	if err := os.Rename(tmp, w.path(d.ID+".json")); err != nil {
+		w.log.Print(err)
+	}
+
+	os.Remove(w.path(d.ID + ".json.sending"))
+}
+
+func (w *Webhooks) remove(d delivery) {
+	if w.config.QueueDir != "" {
+		os.Remove(w.path(d.ID + ".json.sending"))
+	}
+}
+
+func (w *Webhooks) path(name string) string {
+	return filepath.Join(w.config.QueueDir, name)
+}
+
+// Sign returns the X-Ztimer-Signature header of body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,253 @@
+package webhook_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZeroBl21/go-ztimer/pomodoro"
+	"github.com/ZeroBl21/go-ztimer/pomodoro/webhook"
+)
+
+// recorder is a webhook endpoint that fails the first failures requests.
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.requests = append(rec.requests, r)
+	rec.bodies = append(rec.bodies, body)
+
+	if rec.failures > 0 {
+		rec.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+func (rec *recorder) count() int {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return len(rec.requests)
+}
+
+func testEvent(typ string) pomodoro.Event {
+	return pomodoro.Event{
+		Seq:  1,
+		Type: typ,
+		Time: time.Now(),
+		Interval: pomodoro.Interval{
+			ID:              1,
+			StartTime:       time.Now(),
+			PlannedDuration: 25 * time.Minute,
+			Category:        pomodoro.CategoryPomodoro,
+			State:           pomodoro.StateDone,
+		},
+	}
+}
+
+func queued(t *testing.T, dir string) int {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return len(entries)
+}
+
+func TestDeliver(t *testing.T) {
+	testCases := []struct {
+		name    string
+		secret  string
+		events  []string
+		event   string
+		expSent bool
+	}{
+		{name: "Signed", secret: "s3cret", event: pomodoro.EventDone, expSent: true},
+		{name: "Unsigned", event: pomodoro.EventStarted, expSent: true},
+		{name: "NotDefault", event: pomodoro.EventPaused, expSent: false},
+		{name: "Picked", events: []string{pomodoro.EventPaused}, event: pomodoro.EventPaused, expSent: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := &recorder{}
+			srv := httptest.NewServer(rec)
+			defer srv.Close()
+
+			w, err := webhook.New(webhook.Config{
+				Targets: []webhook.Target{{URL: srv.URL, Secret: tc.secret, Events: tc.events}},
+			}, io.Discard)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w.Dispatch(testEvent(tc.event))
+			w.Close()
+
+			if !tc.expSent {
+				if rec.count() != 0 {
+					t.Errorf("Expected no request, got %d instead.\n", rec.count())
+				}
+				return
+			}
+
+			if rec.count() != 1 {
+				t.Fatalf("Expected 1 request, got %d instead.\n", rec.count())
+			}
+
+			r, body := rec.requests[0], rec.bodies[0]
+
+			if h := r.Header.Get("X-Ztimer-Event"); h != tc.event {
+				t.Errorf("Expected event header %q, got %q instead.\n", tc.event, h)
+			}
+
+			sig := r.Header.Get("X-Ztimer-Signature")
+			if tc.secret == "" && sig != "" {
+				t.Errorf("Expected no signature, got %q instead.\n", sig)
+			}
+			if exp := webhook.Sign(tc.secret, body); tc.secret != "" && sig != exp {
+				t.Errorf("Expected signature %q, got %q instead.\n", exp, sig)
+			}
+
+			var e pomodoro.EventJSON
+			if err := json.Unmarshal(body, &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Type != tc.event || e.Interval.ID != 1 {
+				t.Errorf("Expected %s event of interval 1, got %s of %d instead.\n",
+					tc.event, e.Type, e.Interval.ID)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	const backoff = 100 * time.Millisecond
+
+	rec := &recorder{failures: 2}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	var log bytes.Buffer
+	w, err := webhook.New(webhook.Config{
+		Targets:  []webhook.Target{{URL: srv.URL}},
+		QueueDir: dir,
+		Backoff:  backoff,
+	}, &log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Dispatch(testEvent(pomodoro.EventDone))
+
+	// Wait for the first attempt to be queued.
+	deadline := time.Now().Add(5 * time.Second)
+	for queued(t, dir) == 0 || rec.count() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the first attempt")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Not due yet.
+	w.Retry()
+	if rec.count() != 1 {
+		t.Errorf("Expected 1 request before the backoff, got %d instead.\n", rec.count())
+	}
+
+	// Backoff doubles: 100ms, then 200ms.
+	for i, wait := range []time.Duration{backoff, 2 * backoff} {
+		time.Sleep(wait + 20*time.Millisecond)
+		w.Retry()
+
+		if rec.count() != i+2 {
+			t.Errorf("Expected %d requests, got %d instead.\n", i+2, rec.count())
+		}
+	}
+
+	if n := queued(t, dir); n != 0 {
+		t.Errorf("Expected an empty queue, got %d entries instead.\n", n)
+	}
+
+	ids := map[string]bool{}
+	for _, r := range rec.requests {
+		ids[r.Header.Get("X-Ztimer-Delivery")] = true
+	}
+	if len(ids) != 1 {
+		t.Errorf("Expected retries to keep the delivery ID, got %d IDs instead.\n", len(ids))
+	}
+}
+
+func TestGiveUp(t *testing.T) {
+	rec := &recorder{failures: 10}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	w, err := webhook.New(webhook.Config{
+		Targets:     []webhook.Target{{URL: srv.URL}},
+		QueueDir:    dir,
+		Backoff:     time.Millisecond,
+		MaxAttempts: 2,
+	}, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Dispatch(testEvent(pomodoro.EventDone))
+	w.Close()
+
+	time.Sleep(5 * time.Millisecond)
+	w.Retry()
+
+	if rec.count() != 2 {
+		t.Errorf("Expected 2 requests, got %d instead.\n", rec.count())
+	}
+	if n := queued(t, dir); n != 0 {
+		t.Errorf("Expected an empty queue, got %d entries instead.\n", n)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target webhook.Target
+		expErr error
+	}{
+		{name: "NoURL", target: webhook.Target{}, expErr: webhook.ErrNoURL},
+		{name: "Tick", target: webhook.Target{URL: "http://localhost", Events: []string{pomodoro.EventTick}},
+			expErr: webhook.ErrInvalidEvent},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := webhook.New(webhook.Config{Targets: []webhook.Target{tc.target}}, io.Discard)
+
+			if !errors.Is(err, tc.expErr) {
+				t.Errorf("Expected error %q, got %v instead.\n", tc.expErr, err)
+			}
+		})
+	}
+}